@@ -4,13 +4,23 @@ Copyright © 2025 Mahadeva Sankaram
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"path"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
+	"github.com/tmsankaram/password-zen/pkg/breach"
+	"github.com/tmsankaram/password-zen/pkg/policy"
 )
 
 // analyzeCmd represents the analyze command
@@ -27,20 +37,53 @@ For more information on the analysis results, you can use the --output flag to s
 
 func init() {
 	rootCmd.AddCommand(analyzeCmd)
-	analyzeCmd.Flags().StringP("password", "p", "", "Password to analyze")
-	analyzeCmd.Flags().StringP("file", "f", "", "Text file containing passwords to analyze")
+	analyzeCmd.Flags().StringP("password", "p", "", "Password to analyze (visible in shell history and the process list, prefer --prompt or stdin)")
+	analyzeCmd.Flags().StringP("file", "f", "", "Text file containing passwords to analyze, - for stdin")
+	analyzeCmd.Flags().Bool("prompt", false, "Prompt for the password with terminal echo disabled")
+	analyzeCmd.Flags().Bool("confirm", false, "Ask for the prompted password twice")
+	analyzeCmd.Flags().String("input-format", "auto", "Format of --file: auto, lines (one password per line), csv (password column, the other columns are its context, password manager exports are detected) or a password manager export: "+strings.Join(analyzer.InputFormats[2:], ", "))
+	analyzeCmd.Flags().String("password-column", "", "Column holding the passwords of a generic CSV file (default password)")
 
-	// Mark that one of password or file is required
-	analyzeCmd.MarkFlagsMutuallyExclusive("password", "file")
-	analyzeCmd.MarkFlagsOneRequired("password", "file")
+	// Passwords come from one source, stdin when none is given
+	analyzeCmd.MarkFlagsMutuallyExclusive("password", "file", "prompt")
 
 	// Optional flags for analysis criteria
 	analyzeCmd.Flags().StringP("output", "o", "", "Output file for the analysis report")
+	analyzeCmd.Flags().StringP("format", "F", "text", "Report format: text, json, ndjson or csv, with --stats text, json or html")
 	analyzeCmd.Flags().IntP("min-length", "m", 8, "Minimum length for passwords")
 	analyzeCmd.Flags().BoolP("require-symbols", "s", false, "Require passwords to contain special characters")
 	analyzeCmd.Flags().BoolP("require-digits", "d", true, "Require passwords to contain digits")
 	analyzeCmd.Flags().BoolP("require-uppercase", "u", true, "Require passwords to contain uppercase letters")
 	analyzeCmd.Flags().BoolP("require-lowercase", "l", true, "Require passwords to contain lowercase letters")
+	analyzeCmd.Flags().Int("min-score", 3, "Minimum strength score from 0 (very weak) to 4 (very strong)")
+	analyzeCmd.Flags().Int("max-keyboard-walk", 0, "Longest allowed walk of adjacent keys on qwerty, azerty, dvorak or keypad layouts, 0 disables the check")
+	analyzeCmd.Flags().Int("max-sequence", 0, "Longest allowed ascending or descending sequence like abcd or 9753, 0 disables the check")
+	analyzeCmd.Flags().Int("max-repetition", 0, "Longest allowed repeated substring like aaaa or abcabc, 0 disables the check")
+	analyzeCmd.Flags().String("breach-db", "", "Pwned Passwords SHA-1 file (sorted HASH:COUNT lines) or index built with 'breachdb build' to check passwords against")
+	analyzeCmd.Flags().StringArray("context", nil, "Identifier of the user the password must not contain, as key=value, e.g. user=alice or email=alice@example.com (repeatable)")
+	analyzeCmd.Flags().Bool("reuse", false, "Report passwords of the input that are duplicates or near-duplicates of each other, by line number (holds every distinct password in memory)")
+	analyzeCmd.Flags().Int("reuse-distance", analyzer.DefaultReuseDistance, "Largest edit distance between near-duplicates of --reuse, up to 3, 0 only links passwords sharing a base word")
+	analyzeCmd.Flags().Bool("reveal", false, "Show the reused passwords and their base words in the --reuse report")
+	analyzeCmd.Flags().Bool("stats", false, "Report aggregate statistics of the input instead of each password: lengths, character classes, hashcat masks, base words, prefixes, suffixes, years and scores")
+	analyzeCmd.Flags().Int("stats-top", analyzer.DefaultStatsTop, "Number of masks, base words, prefixes, suffixes and years --stats lists")
+	analyzeCmd.Flags().String("show", showNone, "How reports show the passwords: none (index only), masked (first and last character), hash (HMAC keyed with --show-key-file, to correlate reports) or plain. Parts of the password quoted by the checks are hidden unless plain")
+	analyzeCmd.Flags().String("show-key-file", "", "File holding the secret key of --show hash, at least 16 bytes")
+	analyzeCmd.Flags().String("context-file", "", "File of terms, such as company or product names, the passwords must not contain, one per line")
+	analyzeCmd.Flags().StringP("policy", "P", "", "Policy file (YAML or JSON) or built-in preset to validate against: "+strings.Join(policy.Presets(), ", "))
+
+	// A policy replaces the individual requirement flags
+	for _, flag := range []string{"min-length", "require-symbols", "require-digits", "require-uppercase", "require-lowercase", "min-score", "max-keyboard-walk", "max-sequence", "max-repetition"} {
+		analyzeCmd.MarkFlagsMutuallyExclusive("policy", flag)
+	}
+
+	// The statistics replace the report of each password
+	analyzeCmd.MarkFlagsMutuallyExclusive("stats", "reuse")
+	analyzeCmd.MarkFlagsMutuallyExclusive("stats", "show")
+
+	// Thresholds of the exit code, by default any failing password fails the run
+	analyzeCmd.Flags().String("fail-under", "", "Exit with code 1 when less than this share of passwords pass, e.g. 90%")
+	analyzeCmd.Flags().Int("max-weak", -1, "Exit with code 1 when more than this number of passwords fail")
+
 	analyzeCmd.Flags().BoolP("no-color", "", false, "Disable colored output")
 	analyzeCmd.Flags().BoolP("no-animation", "", false, "Disable animations")
 }
@@ -68,215 +111,558 @@ func animateAnalysis(passwordNum int) {
 func analyzePassword(cmd *cobra.Command, args []string) {
 	filepath, _ := cmd.Flags().GetString("file")
 	output, _ := cmd.Flags().GetString("output")
-	minLength, _ := cmd.Flags().GetInt("min-length")
-	requireSymbols, _ := cmd.Flags().GetBool("require-symbols")
-	requireDigits, _ := cmd.Flags().GetBool("require-digits")
-	requireUppercase, _ := cmd.Flags().GetBool("require-uppercase")
-	requireLowercase, _ := cmd.Flags().GetBool("require-lowercase")
+	format, _ := cmd.Flags().GetString("format")
 	noColor, _ := cmd.Flags().GetBool("no-color")
 	noAnimation, _ := cmd.Flags().GetBool("no-animation")
+	breachDBPath, _ := cmd.Flags().GetString("breach-db")
+
+	policyName, _ := cmd.Flags().GetString("policy")
+	failUnder, _ := cmd.Flags().GetString("fail-under")
+	maxWeak, _ := cmd.Flags().GetInt("max-weak")
+
+	gate, err := newAnalysisGate(failUnder, maxWeak, cmd.Flags().Changed("max-weak"))
+	if err != nil {
+		failf(cmd, exitUsage, "Error: %v\n", err)
+		return
+	}
 
 	// Disable color if requested
 	if noColor {
 		color.NoColor = true
 	}
 
-	var passwords []string
-	// check if the file exists, is text file and is readable
-	if filepath != "" {
-		if err := checkFileExists(filepath); err != nil {
-			cmd.PrintErrf("Error reading file: %v\n", err)
+	var criteria analyzer.Criteria
+	if policyName != "" {
+		pol, err := policy.Load(policyName)
+		if err != nil {
+			failf(cmd, exitUsage, "Error loading policy: %v\n", err)
 			return
-		} else {
-			cmd.Printf("Analyzing passwords from file: %s\n", filepath)
-			passwords = analyzeFile(filepath)
 		}
+		criteria = pol.Criteria()
+		breachDBPath = pol.BreachDatabase(breachDBPath)
+		if pol.Breach.Enabled && breachDBPath == "" {
+			cmd.PrintErrf("Warning: policy %s enables breach checks but no database is configured, use --breach-db to check breached passwords\n", pol.Name)
+		}
+	} else {
+		minLength, _ := cmd.Flags().GetInt("min-length")
+		minScore, _ := cmd.Flags().GetInt("min-score")
+		requireSymbols, _ := cmd.Flags().GetBool("require-symbols")
+		requireDigits, _ := cmd.Flags().GetBool("require-digits")
+		requireUppercase, _ := cmd.Flags().GetBool("require-uppercase")
+		requireLowercase, _ := cmd.Flags().GetBool("require-lowercase")
+		criteria = flagCriteria(minLength, minScore, requireSymbols, requireDigits, requireUppercase, requireLowercase)
+		criteria.MaxKeyboardWalk, _ = cmd.Flags().GetInt("max-keyboard-walk")
+		criteria.MaxSequence, _ = cmd.Flags().GetInt("max-sequence")
+		criteria.MaxRepetition, _ = cmd.Flags().GetInt("max-repetition")
 	}
-	if len(passwords) == 0 {
-		password, _ := cmd.Flags().GetString("password")
-		if password == "" {
-			cmd.PrintErr("Error: No password provided for analysis. Use --password or --file to specify a password or file.\n")
+
+	contextValues, _ := cmd.Flags().GetStringArray("context")
+	contextFile, _ := cmd.Flags().GetString("context-file")
+	identifiers, err := parseContext(contextValues)
+	if err != nil {
+		failf(cmd, exitUsage, "Error: %v\n", err)
+		return
+	}
+	if contextFile != "" {
+		terms, err := readContextFile(contextFile)
+		if err != nil {
+			failf(cmd, exitIO, "Error reading context file: %v\n", err)
 			return
 		}
-		passwords = []string{password}
+		identifiers = append(identifiers, terms...)
 	}
+	criteria.ContextTerms = analyzer.ContextTerms(identifiers...)
 
-	if len(passwords) == 0 {
-		cmd.PrintErr("Error: No password provided for analysis. Use --password or --file to specify a password or file.\n")
+	if criteria.MinScore < 0 || criteria.MinScore > 4 {
+		failf(cmd, exitUsage, "Error: Minimum strength score must be between 0 and 4\n")
+		return
+	}
+	if criteria.MaxKeyboardWalk < 0 || criteria.MaxSequence < 0 || criteria.MaxRepetition < 0 {
+		failf(cmd, exitUsage, "Error: Pattern lengths must not be negative\n")
 		return
 	}
 
-	var allResults []string
-	passCount := 0
+	reuse, _ := cmd.Flags().GetBool("reuse")
+	reuseDistance, _ := cmd.Flags().GetInt("reuse-distance")
+	reveal, _ := cmd.Flags().GetBool("reveal")
+	if reuseDistance < 0 || reuseDistance > analyzer.MaxReuseDistance {
+		failf(cmd, exitUsage, "Error: --reuse-distance must be between 0 and %d\n", analyzer.MaxReuseDistance)
+		return
+	}
+	if reveal && !reuse {
+		failf(cmd, exitUsage, "Error: --reveal requires --reuse\n")
+		return
+	}
 
-	for i, password := range passwords {
-		// Show animated analysis
-		if !noAnimation {
-			animateAnalysis(i + 1)
-		} else {
-			fmt.Printf("Analyzing password %d...\n", i+1)
+	show, _ := cmd.Flags().GetString("show")
+	showKeyFile, _ := cmd.Flags().GetString("show-key-file")
+	if show == showHash && showKeyFile == "" {
+		failf(cmd, exitUsage, "Error: --show hash requires --show-key-file\n")
+		return
+	}
+	if show != showHash && showKeyFile != "" {
+		failf(cmd, exitUsage, "Error: --show-key-file requires --show hash\n")
+		return
+	}
+	var showKey []byte
+	if showKeyFile != "" {
+		if showKey, err = readShowKey(showKeyFile); err != nil {
+			failf(cmd, exitIO, "Error reading show key: %v\n", err)
+			return
 		}
+	}
+	redactor, err := newPasswordRedactor(show, showKey)
+	if err != nil {
+		failf(cmd, exitUsage, "Error: %v\n", err)
+		return
+	}
 
-		// Reset analysis for each password
-		var currentAnalysis []string
-		passed := true
+	inputFormat, _ := cmd.Flags().GetString("input-format")
+	passwordColumn, _ := cmd.Flags().GetString("password-column")
+	if inputFormat != "auto" && !slices.Contains(analyzer.InputFormats, inputFormat) {
+		failf(cmd, exitUsage, "Error: Unknown input format %q, expected one of: auto, %s\n", inputFormat, strings.Join(analyzer.InputFormats, ", "))
+		return
+	}
+	if inputFormat == "auto" {
+		inputFormat = detectInputFormat(filepath)
+	}
+	if passwordColumn != "" && inputFormat != analyzer.FormatCSV {
+		failf(cmd, exitUsage, "Error: --password-column requires CSV input\n")
+		return
+	}
 
-		if len(password) < minLength {
-			currentAnalysis = append(currentAnalysis, fmt.Sprintf("  %s Too short (%d < %d characters)", redCross("✗"), len(password), minLength))
-			passed = false
-		} else {
-			currentAnalysis = append(currentAnalysis, fmt.Sprintf("  %s Length: %d characters", greenCheck("✓"), len(password)))
+	stats, _ := cmd.Flags().GetBool("stats")
+	statsTop, _ := cmd.Flags().GetInt("stats-top")
+	if stats {
+		if !slices.Contains(statsFormats, format) {
+			failf(cmd, exitUsage, "Error: Unknown format %q for --stats, expected one of: %s\n", format, strings.Join(statsFormats, ", "))
+			return
 		}
-
-		if requireSymbols && !containsSymbol(password) {
-			currentAnalysis = append(currentAnalysis, fmt.Sprintf("  %s Missing special characters", redCross("✗")))
-			passed = false
-		} else if requireSymbols {
-			currentAnalysis = append(currentAnalysis, fmt.Sprintf("  %s Contains special characters", greenCheck("✓")))
+		if statsTop <= 0 {
+			failf(cmd, exitUsage, "Error: --stats-top must be positive\n")
+			return
 		}
+	} else if !isReportFormat(format) {
+		failf(cmd, exitUsage, "Error: Unknown format %q, expected one of: %s\n", format, strings.Join(reportFormats, ", "))
+		return
+	}
 
-		if requireDigits && !containsDigit(password) {
-			currentAnalysis = append(currentAnalysis, fmt.Sprintf("  %s Missing digits", redCross("✗")))
-			passed = false
-		} else if requireDigits {
-			currentAnalysis = append(currentAnalysis, fmt.Sprintf("  %s Contains digits", greenCheck("✓")))
+	if breachDBPath != "" {
+		if err := checkFileExists(breachDBPath); err != nil {
+			failf(cmd, exitIO, "Error opening breach database: %v\n", err)
+			return
+		}
+		db, err := breach.Open(breachDBPath)
+		if err != nil {
+			failf(cmd, exitIO, "Error opening breach database: %v\n", err)
+			return
 		}
+		defer db.Close()
+		criteria.Breach = db
+	}
 
-		if requireUppercase && !containsUppercase(password) {
-			currentAnalysis = append(currentAnalysis, fmt.Sprintf("  %s Missing uppercase letters", redCross("✗")))
-			passed = false
-		} else if requireUppercase {
-			currentAnalysis = append(currentAnalysis, fmt.Sprintf("  %s Contains uppercase letters", greenCheck("✓")))
+	password, _ := cmd.Flags().GetString("password")
+	prompt, _ := cmd.Flags().GetBool("prompt")
+	confirm, _ := cmd.Flags().GetBool("confirm")
+	if confirm && !prompt {
+		failf(cmd, exitUsage, "Error: --confirm requires --prompt\n")
+		return
+	}
+	if prompt {
+		var err error
+		if password, err = promptPassword(confirm); err != nil {
+			failf(cmd, exitUsage, "Error reading password: %v\n", err)
+			return
 		}
+	} else if filepath == "" && password == "" {
+		if !stdinIsPiped() {
+			failf(cmd, exitUsage, "Error: No password provided for analysis. Use --password, --prompt or --file, or pipe passwords to stdin.\n")
+			return
+		}
+		filepath = "-"
+	}
+	if reuse && filepath == "" {
+		failf(cmd, exitUsage, "Error: --reuse requires --file or passwords on stdin\n")
+		return
+	}
+	if stats && filepath == "" {
+		failf(cmd, exitUsage, "Error: --stats requires --file or passwords on stdin\n")
+		return
+	}
 
-		if requireLowercase && !containsLowercase(password) {
-			currentAnalysis = append(currentAnalysis, fmt.Sprintf("  %s Missing lowercase letters", redCross("✗")))
-			passed = false
-		} else if requireLowercase {
-			currentAnalysis = append(currentAnalysis, fmt.Sprintf("  %s Contains lowercase letters", greenCheck("✓")))
+	var file *os.File
+	var fileSize int64
+	if filepath == "-" {
+		file = os.Stdin
+	} else if filepath != "" {
+		// check if the file exists, is text file and is readable
+		if err := checkFileExists(filepath); err != nil {
+			failf(cmd, exitIO, "Error reading file: %v\n", err)
+			return
+		}
+		var err error
+		if file, err = os.Open(filepath); err != nil {
+			failf(cmd, exitIO, "Error reading file: %v\n", err)
+			return
+		}
+		defer file.Close()
+		if info, err := file.Stat(); err == nil {
+			fileSize = info.Size()
 		}
+	}
 
-		// Format result for this password
-		statusText := func() string {
-			if passed {
-				passCount++
-				return greenText("STRONG") + " " + greenCheck("✓")
-			}
-			return redText("WEAK") + " " + redCross("✗")
-		}()
+	// Reports are written while the passwords are analyzed, so that files of
+	// any size are analyzed in constant memory
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
 
-		result := fmt.Sprintf("%s %d: %s\n", cyanText("Password"), i+1, statusText)
+	var stdoutReport, fileReport reportWriter
+	if format != "text" && !stats {
+		stdoutReport, _ = newReportWriter(out, format)
+	}
 
-		for _, check := range currentAnalysis {
-			result += fmt.Sprintf("%s\n", check)
+	var outputFile *os.File
+	var outputBuffer *bufio.Writer
+	if output != "" {
+		var err error
+		if outputFile, err = os.Create(output); err != nil {
+			failf(cmd, exitIO, "Error writing to output file: %v\n", err)
+			return
 		}
-		result += "\n"
+		defer outputFile.Close()
+		outputBuffer = bufio.NewWriter(outputFile)
+		if !stats {
+			fileReport, _ = newReportWriter(outputBuffer, format)
+		}
+	}
+
+	// The progress of files replaces the per password animation
+	progress := &analysisProgress{out: out, total: fileSize}
+	progress.enabled = file != nil && (format == "text" || stats) && !noAnimation && term.IsTerminal(int(os.Stderr.Fd()))
+
+	var statistics *analyzer.Stats
+	if stats {
+		statistics = analyzer.NewStats()
+	}
+
+	var summary analysisSummary
+	emit := func(index int, result analyzer.Result) error {
+		redactor.redact(&result)
+		record := analysisRecord{Index: index, Result: result}
+		summary.add(record)
 
-		// For file output (without colors)
-		plainResult := fmt.Sprintf("Password %d: %s\n", i+1, func() string {
-			if passed {
-				return "STRONG ✓"
+		if statistics != nil {
+			statistics.AddScore(result.Strength.Score)
+			progress.render(index)
+			return nil
+		}
+		if format == "text" {
+			progress.clear()
+			if noAnimation {
+				fmt.Fprintf(out, "Analyzing password %d...\n", index)
+			}
+			if _, err := out.WriteString(formatTextRecord(record, true)); err != nil {
+				return err
 			}
-			return "WEAK ✗"
-		}())
-		for _, check := range currentAnalysis {
-			// Remove color codes for file output
-			plainCheck := strings.ReplaceAll(check, greenCheck("✓"), "✓")
-			plainCheck = strings.ReplaceAll(plainCheck, redCross("✗"), "✗")
-			plainResult += fmt.Sprintf("%s\n", plainCheck)
+			progress.render(index)
+		} else if err := stdoutReport.writeRecord(record); err != nil {
+			return err
+		}
+		if fileReport != nil {
+			return fileReport.writeRecord(record)
 		}
-		plainResult += "\n"
+		return nil
+	}
 
-		allResults = append(allResults, plainResult)
-		fmt.Print(result)
+	var detector *analyzer.ReuseDetector
+	if file != nil {
+		if file == os.Stdin {
+			cmd.Println("Analyzing passwords from stdin")
+		} else {
+			cmd.Printf("Analyzing passwords from file: %s\n", filepath)
+		}
+		opts := analyzer.StreamOptions{Progress: progress.update, Format: inputFormat, PasswordColumn: passwordColumn}
+		if reuse {
+			detector = analyzer.NewReuseDetector(reuseDistance)
+			opts.Observe = detector.Add
+		}
+		if statistics != nil {
+			opts.Observe = func(line int, password string) { statistics.Add(password) }
+		}
+		if show != showNone {
+			opts.Show = redactor.show
+		}
+		err = analyzer.AnalyzeStream(file, criteria, opts, emit)
+	} else {
+		if format == "text" && !noAnimation {
+			animateAnalysis(1)
+		}
+		var result analyzer.Result
+		if result, err = analyzer.Analyze(password, criteria); err == nil {
+			result.Shown = redactor.show(password)
+			err = emit(1, result)
+		}
+	}
+	progress.clear()
+	if err != nil {
+		out.Flush()
+		failf(cmd, exitIO, "Error analyzing passwords: %v\n", err)
+		if outputFile != nil {
+			outputFile.Close()
+			os.Remove(output)
+		}
+		return
 	}
 
-	// Summary
-	summaryText := func() string {
-		if noColor {
-			return fmt.Sprintf("Summary: %d/%d passwords meet all criteria", passCount, len(passwords))
+	if summary.Total == 0 {
+		failf(cmd, exitUsage, "Error: No password provided for analysis. The input contains no passwords.\n")
+		if outputFile != nil {
+			outputFile.Close()
+			os.Remove(output)
 		}
-		if passCount == len(passwords) {
-			return greenText(fmt.Sprintf("🎉 Excellent! All %d passwords are strong!", len(passwords)))
-		} else if passCount > len(passwords)/2 {
-			return yellowText(fmt.Sprintf("👍 Good! %d/%d passwords meet criteria", passCount, len(passwords)))
-		} else {
-			return redText(fmt.Sprintf("⚠️  Warning! Only %d/%d passwords meet criteria", passCount, len(passwords)))
+		return
+	}
+
+	if detector != nil {
+		summary.Reuse = reuseGroups(detector, reveal)
+	}
+
+	if statistics != nil {
+		if err := writeStatsReport(out, format, statistics.Report(statsTop), summary); err != nil {
+			failf(cmd, exitIO, "Error writing report: %v\n", err)
+			return
+		}
+	} else if format == "text" {
+		if summary.Reuse != nil {
+			fmt.Fprint(out, formatReuseReport(summary.Reuse, true))
 		}
-	}()
 
-	plainSummary := fmt.Sprintf("Summary: %d/%d passwords meet all criteria\n", passCount, len(passwords))
-	allResults = append(allResults, plainSummary)
+		// Summary
+		summaryText := func() string {
+			if noColor {
+				return fmt.Sprintf("Summary: %d/%d passwords meet all criteria", summary.Passed, summary.Total)
+			}
+			if summary.Passed == summary.Total {
+				return greenText(fmt.Sprintf("🎉 Excellent! All %d passwords are strong!", summary.Total))
+			} else if summary.Passed > summary.Total/2 {
+				return yellowText(fmt.Sprintf("👍 Good! %d/%d passwords meet criteria", summary.Passed, summary.Total))
+			} else {
+				return redText(fmt.Sprintf("⚠️  Warning! Only %d/%d passwords meet criteria", summary.Passed, summary.Total))
+			}
+		}()
 
-	fmt.Println(summaryText)
+		fmt.Fprintln(out, summaryText)
+	} else if err := stdoutReport.finish(summary); err != nil {
+		failf(cmd, exitIO, "Error writing report: %v\n", err)
+		return
+	}
+	if err := out.Flush(); err != nil {
+		failf(cmd, exitIO, "Error writing report: %v\n", err)
+		return
+	}
 
 	// Write to file if specified
-	if output != "" {
-		fullOutput := strings.Join(allResults, "")
-		if err := os.WriteFile(output, []byte(fullOutput), 0644); err != nil {
-			cmd.PrintErrf("Error writing to output file: %v\n", err)
+	if outputFile != nil {
+		var err error
+		if statistics != nil {
+			err = writeStatsReport(outputBuffer, format, statistics.Report(statsTop), summary)
+		} else {
+			err = fileReport.finish(summary)
+		}
+		if err == nil {
+			err = outputBuffer.Flush()
+		}
+		if err == nil {
+			err = outputFile.Close()
+		}
+		if err != nil {
+			failf(cmd, exitIO, "Error writing to output file: %v\n", err)
 			return
 		}
 		cmd.Printf("Analysis results written to %s\n", output)
 	}
-}
-func containsSymbol(password string) bool {
-	symbols := "!@#$%^&*()-_=+[]{}|;:,.<>?/"
-	for _, char := range password {
-		if strings.ContainsRune(symbols, char) {
-			return true
+
+	if reason := gate.check(summary); reason != "" {
+		if gate.explicit {
+			cmd.PrintErrf("Failed: %s\n", reason)
 		}
+		exitCode = exitFailed
 	}
-	return false
 }
 
-func containsDigit(password string) bool {
-	for _, char := range password {
-		if char >= '0' && char <= '9' {
-			return true
+// analysisGate decides whether the passwords analyzed fail the run
+type analysisGate struct {
+	// failUnder is the lowest percentage of passing passwords, disabled when negative
+	failUnder float64
+	// maxWeak is the highest number of failing passwords, disabled when negative
+	maxWeak int
+	// explicit is set when a threshold was given rather than the default
+	explicit bool
+}
+
+// newAnalysisGate builds the gate of the --fail-under and --max-weak flags.
+// Without a threshold any failing password fails the run.
+func newAnalysisGate(failUnder string, maxWeak int, maxWeakSet bool) (analysisGate, error) {
+	gate := analysisGate{failUnder: -1, maxWeak: -1}
+	if failUnder != "" {
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(failUnder), "%"), 64)
+		if err != nil || percent < 0 || percent > 100 {
+			return gate, fmt.Errorf("--fail-under must be a percentage between 0%% and 100%%, got %q", failUnder)
+		}
+		gate.failUnder = percent
+		gate.explicit = true
+	}
+	if maxWeakSet {
+		if maxWeak < 0 {
+			return gate, fmt.Errorf("--max-weak must not be negative")
 		}
+		gate.maxWeak = maxWeak
+		gate.explicit = true
 	}
-	return false
+	if !gate.explicit {
+		gate.maxWeak = 0
+	}
+	return gate, nil
 }
 
-func containsUppercase(password string) bool {
-	for _, char := range password {
-		if char >= 'A' && char <= 'Z' {
-			return true
+// check returns why the summary fails the gate, or "" when it passes
+func (g analysisGate) check(summary analysisSummary) string {
+	if g.failUnder >= 0 && summary.Total > 0 {
+		if passed := float64(summary.Passed) * 100 / float64(summary.Total); passed < g.failUnder {
+			return fmt.Sprintf("%.1f%% of passwords meet the criteria, below --fail-under %g%%", passed, g.failUnder)
 		}
 	}
-	return false
+	if g.maxWeak >= 0 && summary.Failed > g.maxWeak {
+		return fmt.Sprintf("%d passwords fail the criteria, more than --max-weak %d", summary.Failed, g.maxWeak)
+	}
+	return ""
+}
+
+// analysisProgress shows the share of a file analyzed so far on stderr. The
+// bytes read are updated by the reading goroutine of analyzer.AnalyzeStream
+// and rendered between records, so the line never interleaves with them.
+type analysisProgress struct {
+	enabled  bool
+	out      *bufio.Writer
+	total    int64
+	read     atomic.Int64
+	last     time.Time
+	rendered bool
+}
+
+func (p *analysisProgress) update(bytesRead int64) {
+	p.read.Store(bytesRead)
+}
+
+// render shows the progress at most every 100ms. Buffered records are
+// flushed first so that they appear above the progress line.
+func (p *analysisProgress) render(analyzed int) {
+	if !p.enabled || time.Since(p.last) < 100*time.Millisecond {
+		return
+	}
+	p.last = time.Now()
+	p.out.Flush()
+
+	line := fmt.Sprintf("%s Analyzed %d passwords", cyanText("🔍"), analyzed)
+	if p.total > 0 {
+		line += fmt.Sprintf(" (%d%%)", p.read.Load()*100/p.total)
+	}
+	fmt.Fprint(os.Stderr, "\r"+line+"   ")
+	p.rendered = true
+}
+
+// clear removes the progress line
+func (p *analysisProgress) clear() {
+	if !p.rendered {
+		return
+	}
+	fmt.Fprint(os.Stderr, "\r"+strings.Repeat(" ", 50)+"\r")
+	p.rendered = false
 }
 
-func containsLowercase(password string) bool {
-	for _, char := range password {
-		if char >= 'a' && char <= 'z' {
-			return true
+// parseContext returns the identifiers of the key=value pairs of --context
+func parseContext(values []string) ([]string, error) {
+	var identifiers []string
+	for _, value := range values {
+		key, identifier, ok := strings.Cut(value, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid context %q, expected key=value such as user=alice", value)
 		}
+		identifiers = append(identifiers, identifier)
 	}
-	return false
+	return identifiers, nil
 }
 
-// analyzeFile reads passwords from a file and returns them as a slice
-func analyzeFile(filepath string) []string {
-	file, err := os.ReadFile(filepath)
+// readContextFile returns the lines of a --context-file, skipping empty lines
+// and lines starting with #
+func readContextFile(filepath string) ([]string, error) {
+	if err := checkFileExists(filepath); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath)
 	if err != nil {
-		fmt.Printf("Error reading file: %v\n", err)
-		return nil
+		return nil, fmt.Errorf("cannot read file: %v", err)
+	}
+
+	var identifiers []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			identifiers = append(identifiers, line)
+		}
 	}
+	return identifiers, nil
+}
 
-	lines := strings.Split(string(file), "\n")
-	var passwords []string
+// inputFormatsByExtension are the input formats --input-format auto picks by
+// file extension, one password per line for other files
+var inputFormatsByExtension = map[string]string{
+	".csv":  analyzer.FormatCSV,
+	".json": analyzer.FormatBitwardenJSON,
+	".xml":  analyzer.FormatKeePassXML,
+}
 
-	// Filter out empty lines
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line != "" {
-			passwords = append(passwords, line)
+// detectInputFormat returns the input format of a file for --input-format auto
+func detectInputFormat(filepath string) string {
+	if format, ok := inputFormatsByExtension[strings.ToLower(path.Ext(filepath))]; ok {
+		return format
+	}
+	return analyzer.FormatLines
+}
+
+// reuseGroups returns the reuse groups of the detector. Unless reveal is set,
+// the passwords and base words are left out, so that the report only points
+// to the lines of the input.
+func reuseGroups(detector *analyzer.ReuseDetector, reveal bool) []analyzer.ReuseGroup {
+	groups := detector.Groups()
+	if groups == nil {
+		// An empty list tells that passwords were compared and none is reused
+		groups = []analyzer.ReuseGroup{}
+	}
+	if !reveal {
+		for i := range groups {
+			groups[i].Passwords, groups[i].BaseWord = nil, ""
 		}
 	}
+	return groups
+}
 
-	return passwords
+// flagCriteria builds the criteria of the individual requirement flags.
+// Each required class needs at least one character.
+func flagCriteria(minLength, minScore int, requireSymbols, requireDigits, requireUppercase, requireLowercase bool) analyzer.Criteria {
+	criteria := analyzer.Criteria{MinLength: minLength, MinScore: minScore}
+	if requireSymbols {
+		criteria.MinSymbols = 1
+	}
+	if requireDigits {
+		criteria.MinDigits = 1
+	}
+	if requireUppercase {
+		criteria.MinUpper = 1
+	}
+	if requireLowercase {
+		criteria.MinLower = 1
+	}
+	return criteria
 }
 
 func checkFileExists(filepath string) error {
@@ -293,11 +679,10 @@ func checkFileExists(filepath string) error {
 		return fmt.Errorf("path is a directory, not a file: %s", filepath)
 	}
 
-	// Try to read the file to check if it's readable
-	_, err = os.ReadFile(filepath)
+	// Try to open the file to check if it's readable, without reading it
+	file, err := os.Open(filepath)
 	if err != nil {
 		return fmt.Errorf("cannot read file: %v", err)
 	}
-
-	return nil
+	return file.Close()
 }
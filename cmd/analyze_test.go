@@ -2,88 +2,85 @@ package cmd
 
 import (
 	"testing"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
 )
 
-func TestContainsSymbol(t *testing.T) {
+func TestAnalysisGate(t *testing.T) {
+	summary := analysisSummary{Total: 10, Passed: 8, Failed: 2}
+
 	tests := []struct {
-		name     string
-		password string
-		want     bool
+		name       string
+		failUnder  string
+		maxWeak    int
+		maxWeakSet bool
+		wantFail   bool
 	}{
-		{"Password with symbol", "test@123", true},
-		{"Password without symbol", "test123", false},
-		{"Password with multiple symbols", "test!@#$", true},
-		{"Empty password", "", false},
+		{"Default fails on any failure", "", -1, false, true},
+		{"Pass rate above threshold", "80%", -1, false, false},
+		{"Pass rate below threshold", "90%", -1, false, true},
+		{"Threshold without percent sign", "75", -1, false, false},
+		{"Weak passwords within limit", "", 2, true, false},
+		{"Weak passwords over limit", "", 1, true, true},
+		{"Both thresholds, one fails", "50%", 1, true, true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := containsSymbol(tt.password); got != tt.want {
-				t.Errorf("containsSymbol() = %v, want %v", got, tt.want)
+			gate, err := newAnalysisGate(tt.failUnder, tt.maxWeak, tt.maxWeakSet)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if reason := gate.check(summary); (reason != "") != tt.wantFail {
+				t.Errorf("check() = %q, want failure %v", reason, tt.wantFail)
 			}
 		})
 	}
-}
 
-func TestContainsDigit(t *testing.T) {
-	tests := []struct {
-		name     string
-		password string
-		want     bool
-	}{
-		{"Password with digit", "test123", true},
-		{"Password without digit", "testABC", false},
-		{"Password with single digit", "test1", true},
-		{"Empty password", "", false},
+	if gate, _ := newAnalysisGate("", -1, false); gate.check(analysisSummary{Total: 3, Passed: 3}) != "" {
+		t.Error("Default gate should pass when all passwords pass")
 	}
+}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := containsDigit(tt.password); got != tt.want {
-				t.Errorf("containsDigit() = %v, want %v", got, tt.want)
-			}
-		})
+func TestAnalysisGateInvalid(t *testing.T) {
+	for _, failUnder := range []string{"abc", "101%", "-5%"} {
+		if _, err := newAnalysisGate(failUnder, -1, false); err == nil {
+			t.Errorf("Expected an error for --fail-under %q", failUnder)
+		}
+	}
+	if _, err := newAnalysisGate("", -1, true); err == nil {
+		t.Error("Expected an error for a negative --max-weak")
 	}
 }
 
-func TestContainsUppercase(t *testing.T) {
-	tests := []struct {
-		name     string
-		password string
-		want     bool
-	}{
-		{"Password with uppercase", "Test123", true},
-		{"Password without uppercase", "test123", false},
-		{"Password with multiple uppercase", "TEST", true},
-		{"Empty password", "", false},
+func TestParseContext(t *testing.T) {
+	identifiers, err := parseContext([]string{"user=alice", "email=alice@example.com", "company=Acme=Corp"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(identifiers) != 3 || identifiers[0] != "alice" || identifiers[2] != "Acme=Corp" {
+		t.Errorf("parseContext() = %v", identifiers)
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := containsUppercase(tt.password); got != tt.want {
-				t.Errorf("containsUppercase() = %v, want %v", got, tt.want)
-			}
-		})
+	for _, value := range []string{"alice", "=alice"} {
+		if _, err := parseContext([]string{value}); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
 	}
 }
 
-func TestContainsLowercase(t *testing.T) {
-	tests := []struct {
-		name     string
-		password string
-		want     bool
-	}{
-		{"Password with lowercase", "Test123", true},
-		{"Password without lowercase", "TEST123", false},
-		{"Password with multiple lowercase", "test", true},
-		{"Empty password", "", false},
+func TestDetectInputFormat(t *testing.T) {
+	tests := map[string]string{
+		"passwords.txt":  analyzer.FormatLines,
+		"vault.CSV":      analyzer.FormatCSV,
+		"bitwarden.json": analyzer.FormatBitwardenJSON,
+		"keepass.xml":    analyzer.FormatKeePassXML,
+		"-":              analyzer.FormatLines,
+		"":               analyzer.FormatLines,
 	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := containsLowercase(tt.password); got != tt.want {
-				t.Errorf("containsLowercase() = %v, want %v", got, tt.want)
-			}
-		})
+	for filepath, want := range tests {
+		if got := detectInputFormat(filepath); got != want {
+			t.Errorf("detectInputFormat(%q) = %q, want %q", filepath, got, want)
+		}
 	}
 }
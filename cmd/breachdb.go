@@ -0,0 +1,79 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tmsankaram/password-zen/pkg/breach"
+)
+
+// breachdbCmd represents the breachdb command
+var breachdbCmd = &cobra.Command{
+	Use:   "breachdb",
+	Short: "Manage local breached password databases",
+	Long: `Manage local breached password databases used by 'analyze --breach-db'.
+The databases are built from Pwned Passwords style SHA-1 corpora (sorted "HASH:COUNT" lines) and are searched completely offline.`,
+}
+
+// breachdbBuildCmd represents the breachdb build command
+var breachdbBuildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Build a compact index from a Pwned Passwords SHA-1 file",
+	Long: `Build a compact binary index from a Pwned Passwords SHA-1 file ordered by hash.
+Each "HASH:COUNT" line is stored as a fixed size 24 byte record, which makes lookups a fast binary search and roughly halves the file size.
+The input must be sorted by hash, as the "ordered by hash" downloads are.
+
+For example:
+  password-zen breachdb build --input pwned-passwords-sha1-ordered-by-hash-v8.txt --output pwned.pzdb
+  password-zen analyze --file passwords.txt --breach-db pwned.pzdb`,
+	Run: buildBreachDB,
+}
+
+func init() {
+	rootCmd.AddCommand(breachdbCmd)
+	breachdbCmd.AddCommand(breachdbBuildCmd)
+
+	breachdbBuildCmd.Flags().StringP("input", "i", "", "Sorted Pwned Passwords SHA-1 text file (HASH:COUNT lines)")
+	breachdbBuildCmd.Flags().StringP("output", "o", "", "Output file for the index")
+	breachdbBuildCmd.MarkFlagRequired("input")
+	breachdbBuildCmd.MarkFlagRequired("output")
+}
+
+func buildBreachDB(cmd *cobra.Command, args []string) {
+	input, _ := cmd.Flags().GetString("input")
+	output, _ := cmd.Flags().GetString("output")
+
+	if err := checkFileExists(input); err != nil {
+		failf(cmd, exitIO, "Error reading input file: %v\n", err)
+		return
+	}
+
+	in, err := os.Open(input)
+	if err != nil {
+		failf(cmd, exitIO, "Error reading input file: %v\n", err)
+		return
+	}
+	defer in.Close()
+
+	out, err := os.Create(output)
+	if err != nil {
+		failf(cmd, exitIO, "Error creating output file: %v\n", err)
+		return
+	}
+
+	records, err := breach.BuildIndex(in, out)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(output)
+		failf(cmd, exitIO, "Error building breach index: %v\n", err)
+		return
+	}
+
+	cmd.Printf("Indexed %d hashes into %s\n", records, output)
+}
@@ -4,12 +4,17 @@ Copyright © 2025 Mahadeva Sankaram
 package cmd
 
 import (
-	"crypto/rand"
+	"bufio"
 	"fmt"
-	"math/big"
+	"os"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/spf13/cobra"
+
+	"github.com/tmsankaram/password-zen/pkg/breach"
+	"github.com/tmsankaram/password-zen/pkg/generator"
+	"github.com/tmsankaram/password-zen/pkg/policy"
 )
 
 // generateCmd represents the generate command
@@ -30,6 +35,32 @@ func init() {
 	generateCmd.Flags().BoolP("include-digits", "d", true, "Include digits defaults to true")
 	generateCmd.Flags().BoolP("exclude-ambiguous", "e", false, "Exclude ambiguous characters like il1Lo0O")
 	generateCmd.Flags().StringP("charset", "c", "", "Custom character set to use for password generation. If not specified, defaults to alphanumeric characters with optional symbols and digits.")
+
+	// Minimum number of characters from each class present in the character set
+	generateCmd.Flags().Int("min-lower", 1, "Minimum number of lowercase letters")
+	generateCmd.Flags().Int("min-upper", 1, "Minimum number of uppercase letters")
+	generateCmd.Flags().Int("min-digits", 1, "Minimum number of digits (when digits are included)")
+	generateCmd.Flags().Int("min-symbols", 1, "Minimum number of special characters (when symbols are included)")
+
+	generateCmd.Flags().String("mode", "random", "Generation mode: random or pronounceable (syllables that are easy to read aloud)")
+	generateCmd.Flags().String("model", generator.ModelSyllables, "Model of pronounceable passwords: "+strings.Join(generator.PronounceableModels, ", "))
+	generateCmd.Flags().String("pattern", "", `Generate passwords of a fixed shape, position by position: c/C consonant, v/V vowel, l/u lowercase/uppercase letter, L letter, 9 digit, # symbol, a letter or digit, * any, hashcat placeholders ?l ?u ?d ?s ?a ?h ?H (?s includes space), \ escapes a literal, e.g. "Cvccvc-99-##" or "?u?l?l?d?d?s"`)
+	generateCmd.Flags().StringArray("define", nil, "Custom placeholder of --pattern as X=chars, usable as X or ?X, e.g. 1=abc or 1=?l?d for ?1 (repeatable)")
+
+	generateCmd.Flags().IntP("count", "n", 1, "Number of passwords to generate")
+	generateCmd.Flags().Bool("unique", false, "Guarantee that the generated passwords are distinct")
+	generateCmd.Flags().StringP("format", "F", "text", "Output format: "+strings.Join(generateFormats, ", "))
+	generateCmd.Flags().String("env-prefix", "PASSWORD", "Variable name prefix of --format env, e.g. PASSWORD_1=...")
+
+	generateCmd.Flags().StringP("policy", "P", "", "Policy file (YAML or JSON) or built-in preset the password must satisfy: "+strings.Join(policy.Presets(), ", "))
+	for _, flag := range []string{"mode", "model", "charset", "min-lower", "min-upper", "min-digits", "min-symbols"} {
+		generateCmd.MarkFlagsMutuallyExclusive("policy", flag)
+	}
+
+	// A pattern gives the length and the characters of every position
+	for _, flag := range []string{"policy", "mode", "model", "length", "charset", "include-symbols", "include-digits", "min-lower", "min-upper", "min-digits", "min-symbols"} {
+		generateCmd.MarkFlagsMutuallyExclusive("pattern", flag)
+	}
 }
 
 func generatePassword(cmd *cobra.Command, args []string) {
@@ -38,74 +69,275 @@ func generatePassword(cmd *cobra.Command, args []string) {
 	includeDigits, _ := cmd.Flags().GetBool("include-digits")
 	excludeAmbiguous, _ := cmd.Flags().GetBool("exclude-ambiguous")
 	customCharset, _ := cmd.Flags().GetString("charset")
+	policyName, _ := cmd.Flags().GetString("policy")
+	mode, _ := cmd.Flags().GetString("mode")
+	count, _ := cmd.Flags().GetInt("count")
+	unique, _ := cmd.Flags().GetBool("unique")
+	format, _ := cmd.Flags().GetString("format")
+	envPrefix, _ := cmd.Flags().GetString("env-prefix")
+	pattern, _ := cmd.Flags().GetString("pattern")
+	defines, _ := cmd.Flags().GetStringArray("define")
 
-	// Validate input
-	if length <= 0 {
-		cmd.PrintErr("Error: Password length must be greater than 0\n")
+	if count <= 0 || count > maxGenerateCount {
+		failf(cmd, exitUsage, "Error: Count must be between 1 and %d\n", maxGenerateCount)
+		return
+	}
+	if !isGenerateFormat(format) {
+		failf(cmd, exitUsage, "Error: Unknown format %q, expected one of: %s\n", format, strings.Join(generateFormats, ", "))
 		return
-	} else if length > 128 {
-		cmd.PrintErr("Error: Password length must not exceed 128 characters\n")
+	}
+	if !envNamePattern.MatchString(envPrefix) {
+		failf(cmd, exitUsage, "Error: --env-prefix %q is not a valid environment variable name\n", envPrefix)
+		return
+	}
+
+	batch := generatedBatch{SchemaVersion: generateSchemaVersion, Mode: mode, Length: length}
+	var next func() (string, float64, error)
+
+	if len(defines) > 0 && !cmd.Flags().Changed("pattern") {
+		failf(cmd, exitUsage, "Error: --define requires --pattern\n")
 		return
 	}
 
-	var charset string
-	if customCharset != "" {
-		charset = customCharset
+	if cmd.Flags().Changed("pattern") {
+		placeholders, err := parseDefines(defines)
+		if err != nil {
+			failf(cmd, exitUsage, "Error: %v\n", err)
+			return
+		}
+		parsed, err := generator.ParsePattern(pattern, generator.PatternOptions{Placeholders: placeholders, ExcludeAmbiguous: excludeAmbiguous})
+		if err != nil {
+			failf(cmd, exitUsage, "Error: Invalid pattern: %v\n", err)
+			return
+		}
+		if parsed.Len() > 128 {
+			failf(cmd, exitUsage, "Error: Password length must not exceed 128 characters\n")
+			return
+		}
+		batch.Mode, batch.Pattern, batch.Length = "pattern", pattern, parsed.Len()
+		entropy := parsed.Entropy()
+		next = func() (string, float64, error) {
+			password, err := parsed.Generate()
+			return password, entropy, err
+		}
+	} else if policyName != "" {
+		pol, err := policy.Load(policyName)
+		if err != nil {
+			failf(cmd, exitUsage, "Error loading policy: %v\n", err)
+			return
+		}
+
+		opts := policy.GenerateOptions{
+			Length:           policyLength(pol, length, cmd.Flags().Changed("length")),
+			IncludeDigits:    includeDigits,
+			IncludeSymbols:   includeSymbols,
+			ExcludeAmbiguous: excludeAmbiguous,
+		}
+		if path := pol.BreachDatabase(""); path != "" {
+			if err := checkFileExists(path); err != nil {
+				failf(cmd, exitIO, "Error opening breach database: %v\n", err)
+				return
+			}
+			db, err := breach.Open(path)
+			if err != nil {
+				failf(cmd, exitIO, "Error opening breach database: %v\n", err)
+				return
+			}
+			defer db.Close()
+			opts.Breach = db
+		}
+
+		classes, err := pol.CharClasses(opts)
+		if err != nil {
+			failf(cmd, exitFailed, "Error generating password: %v\n", err)
+			return
+		}
+		batch.Mode, batch.Policy, batch.Length = "policy", pol.Name, opts.Length
+		batch.Charset = generator.JoinCharClasses(classes)
+		// Passwords failing the policy checks are rejected, so this is an upper bound
+		entropy := generator.Entropy(opts.Length, batch.Charset, classes)
+		next = func() (string, float64, error) {
+			password, err := pol.Generate(opts)
+			return password, entropy, err
+		}
 	} else {
-		charset = buildCharset(includeDigits, includeSymbols, excludeAmbiguous)
+		// Validate input
+		if length <= 0 {
+			failf(cmd, exitUsage, "Error: Password length must be greater than 0\n")
+			return
+		} else if length > 128 {
+			failf(cmd, exitUsage, "Error: Password length must not exceed 128 characters\n")
+			return
+		}
+
+		switch mode {
+		case "random":
+			opts := generator.Options{
+				Length:           length,
+				IncludeDigits:    includeDigits,
+				IncludeSymbols:   includeSymbols,
+				ExcludeAmbiguous: excludeAmbiguous,
+				Charset:          customCharset,
+			}
+
+			minimums := make(map[string]int)
+			explicit := make(map[string]bool)
+			for _, name := range generator.ClassNames {
+				minimums[name], _ = cmd.Flags().GetInt("min-" + name)
+				explicit[name] = cmd.Flags().Changed("min-" + name)
+			}
+			if err := applyClassMinimums(&opts, minimums, explicit, "--min-%s"); err != nil {
+				failf(cmd, exitUsage, "Error: %v\n", err)
+				return
+			}
+
+			batch.Charset = opts.Charset
+			if batch.Charset == "" {
+				batch.Charset = generator.JoinCharClasses(opts.Classes())
+			}
+			entropy := generator.Entropy(length, batch.Charset, opts.Classes())
+			next = func() (string, float64, error) {
+				password, err := generator.Generate(opts)
+				return password, entropy, err
+			}
+		case "pronounceable":
+			opts, err := pronounceableOptions(cmd, length, includeDigits, includeSymbols, excludeAmbiguous)
+			if err != nil {
+				failf(cmd, exitUsage, "Error: %v\n", err)
+				return
+			}
+			batch.Model = opts.Model
+			next = func() (string, float64, error) {
+				return generator.Pronounceable(opts)
+			}
+		default:
+			failf(cmd, exitUsage, "Error: Unknown mode %q, expected random or pronounceable\n", mode)
+			return
+		}
 	}
 
-	if len(charset) == 0 {
-		cmd.PrintErr("Error: No valid characters available for password generation\n")
+	if err := batch.generate(count, unique, next); err != nil {
+		code := exitUsage
+		if policyName != "" {
+			code = exitFailed
+		}
+		failf(cmd, code, "Error generating password: %v\n", err)
 		return
 	}
 
-	password, err := generateSecurePassword(length, charset)
+	out := bufio.NewWriter(os.Stdout)
+	err := writeGenerated(out, format, envPrefix, batch)
+	if err == nil {
+		err = out.Flush()
+	}
 	if err != nil {
-		cmd.PrintErr(fmt.Sprintf("Error generating password: %v\n", err))
+		failf(cmd, exitIO, "Error writing passwords: %v\n", err)
 		return
 	}
 
-	fmt.Println(password)
+	// The entropy of a pronounceable password depends on its letters, the
+	// entropy of a pattern is the same for all of its passwords
+	if format == "text" && (count == 1 && batch.Mode == "pronounceable" || batch.Mode == "pattern") {
+		cmd.Printf("Entropy: %.1f bits\n", batch.Passwords[0].EntropyBits)
+	}
 }
 
-func buildCharset(includeDigits, includeSymbols, excludeAmbiguous bool) string {
-	// Start with base letters
-	charset := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+// pronounceableOptions returns the options of --mode pronounceable. The class
+// minimums give the number of uppercase letters, digits and symbols.
+func pronounceableOptions(cmd *cobra.Command, length int, includeDigits, includeSymbols, excludeAmbiguous bool) (generator.PronounceableOptions, error) {
+	model, _ := cmd.Flags().GetString("model")
+	if cmd.Flags().Changed("charset") {
+		return generator.PronounceableOptions{}, fmt.Errorf("--charset cannot be used with --mode pronounceable")
+	}
 
-	// Add digits if requested
+	opts := generator.PronounceableOptions{
+		Length:           length,
+		Model:            model,
+		ExcludeAmbiguous: excludeAmbiguous,
+	}
+	opts.Upper, _ = cmd.Flags().GetInt("min-upper")
 	if includeDigits {
-		charset += "0123456789"
+		opts.Digits, _ = cmd.Flags().GetInt("min-digits")
 	}
-
-	// Add symbols if requested
 	if includeSymbols {
-		charset += "!@#$%^&*()-_=+[]{}|;:,.<>?/"
+		opts.Symbols, _ = cmd.Flags().GetInt("min-symbols")
 	}
+	return opts, nil
+}
 
-	// Remove ambiguous characters if requested
-	if excludeAmbiguous {
-		ambiguous := "il1Lo0O"
-		for _, char := range ambiguous {
-			charset = strings.ReplaceAll(charset, string(char), "")
+// parseDefines returns the custom placeholders of the X=chars values of --define
+func parseDefines(values []string) (map[rune]string, error) {
+	placeholders := make(map[rune]string)
+	for _, value := range values {
+		name, chars, ok := strings.Cut(value, "=")
+		if !ok || utf8.RuneCountInString(name) != 1 {
+			return nil, fmt.Errorf("invalid placeholder %q, expected a single character and its characters such as 1=abc", value)
 		}
+		placeholder, _ := utf8.DecodeRuneInString(name)
+		if placeholder == '?' || placeholder == '\\' {
+			return nil, fmt.Errorf("%c cannot be defined as a placeholder", placeholder)
+		}
+		if chars == "" {
+			return nil, fmt.Errorf("placeholder %c has no characters", placeholder)
+		}
+		placeholders[placeholder] = chars
 	}
+	return placeholders, nil
+}
 
-	return charset
+// policyLength returns the length to generate for a policy. Unless the length
+// was given explicitly, the default length is moved into the policy's range.
+func policyLength(pol policy.Policy, length int, explicit bool) int {
+	if explicit {
+		return length
+	}
+	length = max(length, pol.MinLength)
+	if pol.MaxLength > 0 {
+		length = min(length, pol.MaxLength)
+	}
+	return length
 }
 
-func generateSecurePassword(length int, charset string) (string, error) {
-	if length <= 0 || len(charset) == 0 {
-		return "", fmt.Errorf("invalid parameters")
+// applyClassMinimums applies the per-class minimums to the classes available in
+// the character set of opts. Minimums of missing classes are dropped unless
+// they were set explicitly, which is an error. Implicit minimums shrink, the
+// last classes first, so that they fit in short passwords; explicit ones that
+// do not fit are left for the generator to report. option formats the name of
+// the option setting a minimum in error messages, e.g. "--min-%s".
+func applyClassMinimums(opts *generator.Options, minimums map[string]int, explicit map[string]bool, option string) error {
+	present := make(map[string]bool)
+	for _, class := range opts.Classes() {
+		present[class.Name] = true
 	}
-	// use crypto/rand for secure random generation
-	result := make([]byte, length)
-	for i := range result {
-		index, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
-		if err != nil {
-			return "", fmt.Errorf("error generating random index: %v", err)
+
+	fields := map[string]*int{
+		"lower":   &opts.MinLower,
+		"upper":   &opts.MinUpper,
+		"digits":  &opts.MinDigits,
+		"symbols": &opts.MinSymbols,
+	}
+	for _, name := range generator.ClassNames {
+		minCount := minimums[name]
+		if minCount < 0 {
+			return fmt.Errorf(option+" must not be negative", name)
+		}
+
+		if present[name] {
+			*fields[name] = minCount
+		} else if minCount > 0 && explicit[name] {
+			return fmt.Errorf(option+" requires %s in the character set", name, name)
+		}
+	}
+
+	excess := opts.MinLower + opts.MinUpper + opts.MinDigits + opts.MinSymbols - opts.Length
+	for i := len(generator.ClassNames) - 1; i >= 0 && excess > 0; i-- {
+		name := generator.ClassNames[i]
+		if explicit[name] {
+			continue
 		}
-		result[i] = charset[index.Int64()]
+		cut := min(*fields[name], excess)
+		*fields[name] -= cut
+		excess -= cut
 	}
-	return string(result), nil
+	return nil
 }
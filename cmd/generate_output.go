@@ -0,0 +1,136 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// generateSchemaVersion is bumped whenever a field of the JSON output of
+// 'generate' changes
+const generateSchemaVersion = 1
+
+// maxGenerateCount bounds --count, the passwords are held in memory
+const maxGenerateCount = 1000000
+
+// maxDuplicateAttempts bounds the duplicates --unique skips in a row before
+// giving up
+const maxDuplicateAttempts = 1000
+
+// generateFormats lists the supported output formats of 'generate'
+var generateFormats = []string{"text", "json", "csv", "env"}
+
+func isGenerateFormat(format string) bool {
+	for _, f := range generateFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// envNamePattern matches portable environment variable names
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// generatedPassword is a generated password with its entropy in bits
+type generatedPassword struct {
+	Password    string  `json:"password"`
+	EntropyBits float64 `json:"entropy_bits"`
+}
+
+// generatedBatch is the JSON output of 'generate'. Charset is empty in
+// pronounceable mode, whose letters come from the model, and in pattern mode,
+// whose characters come from the pattern.
+type generatedBatch struct {
+	SchemaVersion int                 `json:"schema_version"`
+	Mode          string              `json:"mode"`
+	Policy        string              `json:"policy,omitempty"`
+	Model         string              `json:"model,omitempty"`
+	Pattern       string              `json:"pattern,omitempty"`
+	Length        int                 `json:"length"`
+	Charset       string              `json:"charset,omitempty"`
+	Count         int                 `json:"count"`
+	Passwords     []generatedPassword `json:"passwords"`
+}
+
+// generate adds count passwords returned by next to the batch. With unique
+// set, duplicates are skipped; options that allow fewer distinct passwords
+// than requested are an error.
+func (b *generatedBatch) generate(count int, unique bool, next func() (string, float64, error)) error {
+	seen := make(map[string]bool)
+	duplicates := 0
+	for len(b.Passwords) < count {
+		password, entropy, err := next()
+		if err != nil {
+			return err
+		}
+
+		if unique {
+			if len(b.Passwords) == 0 && math.Log2(float64(count)) > entropy {
+				return fmt.Errorf("the options allow only about %.0f distinct passwords, fewer than %d", math.Exp2(entropy), count)
+			}
+			if seen[password] {
+				if duplicates++; duplicates > maxDuplicateAttempts {
+					return fmt.Errorf("only %d distinct passwords found, the options allow too few for %d", len(b.Passwords), count)
+				}
+				continue
+			}
+			seen[password] = true
+			duplicates = 0
+		}
+		b.Passwords = append(b.Passwords, generatedPassword{Password: password, EntropyBits: entropy})
+	}
+	b.Count = len(b.Passwords)
+	return nil
+}
+
+// writeGenerated writes the passwords of the batch:
+//
+//	text: one password per line
+//	json: the batch with its charset and the entropy of every password
+//	csv:  index,password,entropy_bits rows with a header
+//	env:  PREFIX_1='...' lines, single quoted for POSIX shells
+func writeGenerated(w io.Writer, format, envPrefix string, batch generatedBatch) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(batch)
+	case "csv":
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"index", "password", "entropy_bits"})
+		for i, generated := range batch.Passwords {
+			writer.Write([]string{strconv.Itoa(i + 1), generated.Password, strconv.FormatFloat(generated.EntropyBits, 'f', 2, 64)})
+		}
+		writer.Flush()
+		return writer.Error()
+	case "env":
+		for i, generated := range batch.Passwords {
+			if _, err := fmt.Fprintf(w, "%s_%d=%s\n", envPrefix, i+1, shellQuote(generated.Password)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		for _, generated := range batch.Passwords {
+			if _, err := fmt.Fprintln(w, generated.Password); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// shellQuote single quotes s for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -1,112 +1,132 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
+
+	"github.com/tmsankaram/password-zen/pkg/generator"
 )
 
-func TestBuildCharset(t *testing.T) {
-	tests := []struct {
-		name              string
-		includeDigits     bool
-		includeSymbols    bool
-		excludeAmbiguous  bool
-		expectContains    []string
-		expectNotContains []string
-	}{
-		{
-			name:           "Basic charset with digits",
-			includeDigits:  true,
-			includeSymbols: false,
-			expectContains: []string{"a", "Z", "0", "9"},
-		},
-		{
-			name:           "Charset with symbols",
-			includeDigits:  true,
-			includeSymbols: true,
-			expectContains: []string{"a", "Z", "0", "!", "@"},
-		},
-		{
-			name:              "Exclude ambiguous characters",
-			includeDigits:     true,
-			includeSymbols:    false,
-			excludeAmbiguous:  true,
-			expectNotContains: []string{"i", "l", "1", "L", "o", "0", "O"},
-		},
+func testBatch() generatedBatch {
+	return generatedBatch{
+		SchemaVersion: generateSchemaVersion,
+		Mode:          "random",
+		Length:        5,
+		Charset:       "ab<'",
+		Count:         2,
+		Passwords:     []generatedPassword{{"ab<'a", 10}, {"bbbbb", 10}},
 	}
+}
+
+func TestWriteGenerated(t *testing.T) {
+	var buf bytes.Buffer
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			charset := buildCharset(tt.includeDigits, tt.includeSymbols, tt.excludeAmbiguous)
+	if err := writeGenerated(&buf, "text", "PASSWORD", testBatch()); err != nil || buf.String() != "ab<'a\nbbbbb\n" {
+		t.Errorf("text output = %q, %v", buf.String(), err)
+	}
 
-			for _, char := range tt.expectContains {
-				if !strings.Contains(charset, char) {
-					t.Errorf("Expected charset to contain '%s', but it didn't. Charset: %s", char, charset)
-				}
-			}
+	buf.Reset()
+	writeGenerated(&buf, "env", "DB_PASS", testBatch())
+	if want := "DB_PASS_1='ab<'\\''a'\nDB_PASS_2='bbbbb'\n"; buf.String() != want {
+		t.Errorf("env output = %q, want %q", buf.String(), want)
+	}
 
-			for _, char := range tt.expectNotContains {
-				if strings.Contains(charset, char) {
-					t.Errorf("Expected charset to NOT contain '%s', but it did. Charset: %s", char, charset)
-				}
-			}
-		})
+	buf.Reset()
+	writeGenerated(&buf, "csv", "PASSWORD", testBatch())
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil || len(rows) != 3 || strings.Join(rows[1], ",") != "1,ab<'a,10.00" {
+		t.Errorf("csv rows = %v, %v", rows, err)
+	}
+
+	buf.Reset()
+	writeGenerated(&buf, "json", "PASSWORD", testBatch())
+	if strings.Contains(buf.String(), `\u003c`) {
+		t.Errorf("json output escapes HTML characters: %s", buf.String())
+	}
+	var decoded generatedBatch
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || decoded.Charset != "ab<'" || len(decoded.Passwords) != 2 {
+		t.Errorf("json output = %+v, %v", decoded, err)
 	}
 }
 
-func TestGenerateSecurePassword(t *testing.T) {
-	tests := []struct {
-		name    string
-		length  int
-		charset string
-		wantErr bool
-	}{
-		{
-			name:    "Valid password generation",
-			length:  12,
-			charset: "abcdefghijklmnopqrstuvwxyz",
-			wantErr: false,
-		},
-		{
-			name:    "Zero length should error",
-			length:  0,
-			charset: "abc",
-			wantErr: true,
-		},
-		{
-			name:    "Empty charset should error",
-			length:  12,
-			charset: "",
-			wantErr: true,
-		},
+func TestGeneratedBatchUnique(t *testing.T) {
+	// The source repeats every password twice
+	calls := 0
+	next := func() (string, float64, error) {
+		calls++
+		return fmt.Sprint(calls / 2), 20, nil
+	}
+
+	var batch generatedBatch
+	if err := batch.generate(5, true, next); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	seen := make(map[string]bool)
+	for _, generated := range batch.Passwords {
+		if seen[generated.Password] {
+			t.Errorf("Duplicate password %q", generated.Password)
+		}
+		seen[generated.Password] = true
+	}
+	if batch.Count != 5 {
+		t.Errorf("Count = %d, want 5", batch.Count)
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			password, err := generateSecurePassword(tt.length, tt.charset)
+	batch = generatedBatch{}
+	if err := batch.generate(3, true, func() (string, float64, error) { return "a", 1, nil }); err == nil {
+		t.Error("Expected an error when the options allow fewer passwords than requested")
+	}
+	batch = generatedBatch{}
+	if err := batch.generate(3, true, func() (string, float64, error) { return "a", 20, nil }); err == nil {
+		t.Error("Expected an error when only duplicates are generated")
+	}
+}
 
-			if tt.wantErr {
-				if err == nil {
-					t.Errorf("Expected error but got none")
-				}
-				return
-			}
+func TestParseDefines(t *testing.T) {
+	placeholders, err := parseDefines([]string{"1=abc", "ü=äöü", "#=!?", "2=a=b"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fmt.Sprint(placeholders) != fmt.Sprint(map[rune]string{'1': "abc", 'ü': "äöü", '#': "!?", '2': "a=b"}) {
+		t.Errorf("parseDefines() = %v", placeholders)
+	}
 
-			if err != nil {
-				t.Errorf("Unexpected error: %v", err)
-				return
-			}
+	for _, value := range []string{"abc", "12=abc", "=abc", "1=", "?=abc", `\=abc`} {
+		if _, err := parseDefines([]string{value}); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
 
-			if len(password) != tt.length {
-				t.Errorf("Expected password length %d, got %d", tt.length, len(password))
-			}
+func TestApplyClassMinimumsShortLength(t *testing.T) {
+	minimums := map[string]int{"lower": 1, "upper": 1, "digits": 1, "symbols": 1}
+	for length := 1; length <= 4; length++ {
+		opts := generator.Options{Length: length, IncludeDigits: true, IncludeSymbols: true}
+		if err := applyClassMinimums(&opts, minimums, nil, "--min-%s"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if sum := opts.MinLower + opts.MinUpper + opts.MinDigits + opts.MinSymbols; sum != length {
+			t.Errorf("Length %d: minimums add up to %d", length, sum)
+		}
+		if _, err := generator.Generate(opts); err != nil {
+			t.Errorf("Length %d: unexpected error: %v", length, err)
+		}
+	}
 
-			// Check that all characters in password are from charset
-			for _, char := range password {
-				if !strings.Contains(tt.charset, string(char)) {
-					t.Errorf("Password contains character '%c' not in charset '%s'", char, tt.charset)
-				}
-			}
-		})
+	// Explicit minimums are kept, and the generator rejects them
+	opts := generator.Options{Length: 2, IncludeDigits: true, IncludeSymbols: true}
+	if err := applyClassMinimums(&opts, minimums, map[string]bool{"digits": true, "symbols": true}, "--min-%s"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.MinLower != 0 || opts.MinUpper != 0 || opts.MinDigits != 1 || opts.MinSymbols != 1 {
+		t.Errorf("Unexpected minimums %+v", opts)
+	}
+	opts.MinDigits = 3
+	if _, err := generator.Generate(opts); err == nil {
+		t.Errorf("Expected an error for minimums exceeding the length")
 	}
 }
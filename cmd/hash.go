@@ -0,0 +1,144 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tmsankaram/password-zen/pkg/generator"
+	"github.com/tmsankaram/password-zen/pkg/hasher"
+)
+
+// hashCmd represents the hash command
+var hashCmd = &cobra.Command{
+	Use:   "hash",
+	Short: "Hash a password for storage",
+	Long: `Hash a password with argon2id, bcrypt, scrypt or PBKDF2-SHA256 for storage in a user database.
+The password is read from the first line of stdin, prompted for with echo disabled at a terminal, or generated with --generate.
+The hash is printed in the PHC string format, bcrypt hashes in the Modular Crypt Format. Check passwords against it with 'password-zen verify'.
+The default cost parameters follow the OWASP Password Storage Cheat Sheet.`,
+	Args: cobra.NoArgs,
+	Run:  hashPassword,
+}
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify <hash>",
+	Short: "Verify a password against a stored hash",
+	Long: `Verify a password against a hash created by 'password-zen hash' or another tool using the same formats.
+The password is read from the first line of stdin, or prompted for with echo disabled at a terminal.
+Exits with 0 when the password matches, 1 when it does not and 2 when the hash cannot be parsed.`,
+	Args: cobra.ExactArgs(1),
+	Run:  verifyPassword,
+}
+
+func init() {
+	rootCmd.AddCommand(hashCmd)
+	rootCmd.AddCommand(verifyCmd)
+
+	defaults := hasher.DefaultOptions("")
+	hashCmd.Flags().StringP("algorithm", "a", hasher.Argon2id, "Hash algorithm: "+strings.Join(hasher.Algorithms, ", "))
+	hashCmd.Flags().Uint32("time", defaults.Time, "argon2id passes over memory")
+	hashCmd.Flags().Uint32("memory", defaults.Memory, "argon2id memory in KiB")
+	hashCmd.Flags().Uint8("threads", defaults.Threads, "argon2id parallelism")
+	hashCmd.Flags().Int("cost", defaults.Cost, "bcrypt cost (log2 of the rounds)")
+	hashCmd.Flags().Int("scrypt-n", defaults.N, "scrypt CPU/memory cost, a power of 2")
+	hashCmd.Flags().Int("scrypt-r", defaults.R, "scrypt block size")
+	hashCmd.Flags().Int("scrypt-p", defaults.P, "scrypt parallelism")
+	hashCmd.Flags().Int("iterations", defaults.Iterations, "PBKDF2-SHA256 iterations")
+
+	hashCmd.Flags().Bool("generate", false, "Hash a freshly generated password, which is printed to stderr")
+	hashCmd.Flags().IntP("length", "l", 16, "Length of the generated password")
+	hashCmd.Flags().Bool("confirm", false, "Ask for the prompted password twice")
+	hashCmd.MarkFlagsMutuallyExclusive("generate", "confirm")
+}
+
+func hashPassword(cmd *cobra.Command, args []string) {
+	algorithm, _ := cmd.Flags().GetString("algorithm")
+	generate, _ := cmd.Flags().GetBool("generate")
+	length, _ := cmd.Flags().GetInt("length")
+	confirm, _ := cmd.Flags().GetBool("confirm")
+
+	opts := hasher.DefaultOptions(algorithm)
+	opts.Time, _ = cmd.Flags().GetUint32("time")
+	opts.Memory, _ = cmd.Flags().GetUint32("memory")
+	opts.Threads, _ = cmd.Flags().GetUint8("threads")
+	opts.Cost, _ = cmd.Flags().GetInt("cost")
+	opts.N, _ = cmd.Flags().GetInt("scrypt-n")
+	opts.R, _ = cmd.Flags().GetInt("scrypt-r")
+	opts.P, _ = cmd.Flags().GetInt("scrypt-p")
+	opts.Iterations, _ = cmd.Flags().GetInt("iterations")
+	if err := opts.Validate(); err != nil {
+		failf(cmd, exitUsage, "Error: %v\n", err)
+		return
+	}
+
+	var password string
+	if generate {
+		if length <= 0 || length > 128 {
+			failf(cmd, exitUsage, "Error: Password length must be between 1 and 128 characters\n")
+			return
+		}
+		var err error
+		password, err = generator.Generate(generator.Options{
+			Length:         length,
+			IncludeDigits:  true,
+			IncludeSymbols: true,
+			MinLower:       1,
+			MinUpper:       1,
+			MinDigits:      1,
+			MinSymbols:     1,
+		})
+		if err != nil {
+			failf(cmd, exitUsage, "Error generating password: %v\n", err)
+			return
+		}
+		cmd.Printf("Password: %s\n", password)
+	} else {
+		var err error
+		if password, err = readPassword(confirm); err != nil {
+			failf(cmd, exitUsage, "Error reading password: %v\n", err)
+			return
+		}
+	}
+
+	hash, err := hasher.Hash(password, opts)
+	if err != nil {
+		failf(cmd, exitUsage, "Error hashing password: %v\n", err)
+		return
+	}
+	fmt.Println(hash)
+}
+
+func verifyPassword(cmd *cobra.Command, args []string) {
+	if _, err := hasher.Identify(args[0]); err != nil {
+		failf(cmd, exitUsage, "Error: %v\n", err)
+		return
+	}
+
+	password, err := readPassword(false)
+	if err != nil {
+		failf(cmd, exitUsage, "Error reading password: %v\n", err)
+		return
+	}
+
+	ok, err := hasher.Verify(password, args[0])
+	if errors.Is(err, hasher.ErrMalformedHash) {
+		failf(cmd, exitUsage, "Error: %v\n", err)
+		return
+	} else if err != nil {
+		failf(cmd, exitUsage, "Error verifying password: %v\n", err)
+		return
+	}
+
+	if !ok {
+		failf(cmd, exitFailed, "Password does not match\n")
+		return
+	}
+	cmd.Println("Password matches")
+}
@@ -0,0 +1,215 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tmsankaram/password-zen/pkg/hasher"
+)
+
+// hashTuneCmd represents the hash tune command
+var hashTuneCmd = &cobra.Command{
+	Use:   "tune",
+	Short: "Calibrate hash costs for this machine",
+	Long: `Run trial argon2id, bcrypt and scrypt hashes on this machine and recommend the most expensive parameters that hash within the target time and memory limit.
+The recommendation is printed as a YAML snippet ready to paste into a configuration, and --report writes the measurements as JSON.
+Run it on the hardware that will verify passwords in production, and again whenever that hardware changes.`,
+	Args: cobra.NoArgs,
+	Run:  tuneHash,
+}
+
+func init() {
+	hashCmd.AddCommand(hashTuneCmd)
+
+	hashTuneCmd.Flags().Duration("target", 250*time.Millisecond, "Hashing time to approach without exceeding it")
+	hashTuneCmd.Flags().String("memory-max", "256MiB", "Most memory a single argon2id or scrypt hash may use, in KiB, MiB or GiB")
+	hashTuneCmd.Flags().Uint8("threads", 1, "argon2id parallelism")
+	hashTuneCmd.Flags().String("report", "", "Output file for the JSON report, - for stdout instead of the snippet")
+}
+
+// tuneReportSchemaVersion is the version of the JSON report of 'hash tune'
+const tuneReportSchemaVersion = 1
+
+// tuneReport is the JSON report of 'hash tune'
+type tuneReport struct {
+	SchemaVersion   int                  `json:"schema_version"`
+	TargetMS        float64              `json:"target_ms"`
+	MemoryMaxKiB    uint32               `json:"memory_max_kib"`
+	Platform        tunePlatform         `json:"platform"`
+	Recommendations []tuneRecommendation `json:"recommendations"`
+}
+
+type tunePlatform struct {
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+	CPUs int    `json:"cpus"`
+}
+
+type tuneRecommendation struct {
+	Algorithm  string         `json:"algorithm"`
+	Parameters map[string]int `json:"parameters"`
+	Params     string         `json:"params"`
+	DurationMS float64        `json:"duration_ms"`
+	MemoryKiB  uint64         `json:"memory_kib,omitempty"`
+}
+
+func tuneHash(cmd *cobra.Command, args []string) {
+	target, _ := cmd.Flags().GetDuration("target")
+	memoryMax, _ := cmd.Flags().GetString("memory-max")
+	threads, _ := cmd.Flags().GetUint8("threads")
+	reportPath, _ := cmd.Flags().GetString("report")
+
+	memory, err := parseKiB(memoryMax)
+	if err != nil {
+		failf(cmd, exitUsage, "Error: --memory-max: %v\n", err)
+		return
+	}
+
+	cmd.Printf("Tuning %s for %s with at most %s per hash...\n", strings.Join(hasher.TunedAlgorithms, ", "), target, memoryMax)
+	recommendations, err := hasher.Tune(hasher.TuneOptions{Target: target, MemoryMax: memory, Threads: threads})
+	if err != nil {
+		failf(cmd, exitUsage, "Error: %v\n", err)
+		return
+	}
+
+	report := tuneReport{
+		SchemaVersion: tuneReportSchemaVersion,
+		TargetMS:      durationMS(target),
+		MemoryMaxKiB:  memory,
+		Platform:      tunePlatform{OS: runtime.GOOS, Arch: runtime.GOARCH, CPUs: runtime.NumCPU()},
+	}
+	for _, recommendation := range recommendations {
+		report.Recommendations = append(report.Recommendations, newTuneRecommendation(recommendation))
+	}
+
+	switch reportPath {
+	case "-":
+		err = writeTuneReport(os.Stdout, report)
+	case "":
+		err = writeTuneSnippet(os.Stdout, report)
+	default:
+		if err = writeTuneSnippet(os.Stdout, report); err != nil {
+			break
+		}
+		var file *os.File
+		if file, err = os.Create(reportPath); err != nil {
+			break
+		}
+		err = writeTuneReport(file, report)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			cmd.Printf("Report written to %s\n", reportPath)
+		}
+	}
+	if err != nil {
+		failf(cmd, exitIO, "Error writing report: %v\n", err)
+	}
+}
+
+func newTuneRecommendation(recommendation hasher.Recommendation) tuneRecommendation {
+	opts := recommendation.Options
+	tuned := tuneRecommendation{
+		Algorithm:  opts.Algorithm,
+		Params:     opts.Params(),
+		DurationMS: durationMS(recommendation.Duration),
+	}
+	switch opts.Algorithm {
+	case hasher.Argon2id:
+		tuned.Parameters = map[string]int{"memory_kib": int(opts.Memory), "time": int(opts.Time), "threads": int(opts.Threads)}
+		tuned.MemoryKiB = uint64(opts.Memory)
+	case hasher.Bcrypt:
+		tuned.Parameters = map[string]int{"cost": opts.Cost}
+	case hasher.Scrypt:
+		tuned.Parameters = map[string]int{"n": opts.N, "r": opts.R, "p": opts.P}
+		tuned.MemoryKiB = uint64(128*opts.N*opts.R) / 1024
+	}
+	return tuned
+}
+
+// writeTuneSnippet writes the recommendation as YAML with the measurements
+// and matching 'password-zen hash' flags as comments
+func writeTuneSnippet(w io.Writer, report tuneReport) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Tuned on %s/%s with %d CPUs for %gms and at most %d KiB per hash\n",
+		report.Platform.OS, report.Platform.Arch, report.Platform.CPUs, report.TargetMS, report.MemoryMaxKiB)
+	b.WriteString("password_hashing:\n")
+	for _, tuned := range report.Recommendations {
+		fmt.Fprintf(&b, "  # %.0fms, password-zen hash --algorithm %s%s\n", tuned.DurationMS, tuned.Algorithm, hashFlags(tuned))
+		fmt.Fprintf(&b, "  %s:\n", tuned.Algorithm)
+		for _, name := range []string{"memory_kib", "time", "threads", "cost", "n", "r", "p"} {
+			if value, ok := tuned.Parameters[name]; ok {
+				fmt.Fprintf(&b, "    %s: %d\n", name, value)
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// hashFlags returns the 'password-zen hash' flags of a recommendation
+func hashFlags(tuned tuneRecommendation) string {
+	flags := map[string]string{
+		"memory_kib": "--memory",
+		"time":       "--time",
+		"threads":    "--threads",
+		"cost":       "--cost",
+		"n":          "--scrypt-n",
+		"r":          "--scrypt-r",
+		"p":          "--scrypt-p",
+	}
+	var b strings.Builder
+	for _, name := range []string{"memory_kib", "time", "threads", "cost", "n", "r", "p"} {
+		if value, ok := tuned.Parameters[name]; ok {
+			fmt.Fprintf(&b, " %s %d", flags[name], value)
+		}
+	}
+	return b.String()
+}
+
+func writeTuneReport(w io.Writer, report tuneReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+func durationMS(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// parseKiB parses a memory size such as "256MiB" into KiB. Sizes without a
+// unit are in KiB.
+func parseKiB(size string) (uint32, error) {
+	units := []struct {
+		suffix string
+		factor uint64
+	}{{"GiB", 1 << 20}, {"MiB", 1 << 10}, {"KiB", 1}, {"", 1}}
+
+	size = strings.TrimSpace(size)
+	for _, unit := range units {
+		if !strings.HasSuffix(size, unit.suffix) {
+			continue
+		}
+		value, err := strconv.ParseUint(strings.TrimSpace(strings.TrimSuffix(size, unit.suffix)), 10, 32)
+		if err != nil || value == 0 {
+			return 0, fmt.Errorf("invalid size %q, expected a positive number of KiB, MiB or GiB", size)
+		}
+		if value*unit.factor > 1<<32-1 {
+			return 0, fmt.Errorf("size %q is too large", size)
+		}
+		return uint32(value * unit.factor), nil
+	}
+	return 0, fmt.Errorf("invalid size %q", size)
+}
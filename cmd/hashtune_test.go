@@ -0,0 +1,33 @@
+package cmd
+
+import "testing"
+
+func TestParseKiB(t *testing.T) {
+	tests := []struct {
+		size    string
+		want    uint32
+		wantErr bool
+	}{
+		{"256MiB", 256 * 1024, false},
+		{"1GiB", 1 << 20, false},
+		{"19456KiB", 19456, false},
+		{"64", 64, false},
+		{" 2 MiB ", 2048, false},
+		{"0MiB", 0, true},
+		{"256MB", 0, true},
+		{"lots", 0, true},
+		{"8192GiB", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			got, err := parseKiB(tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseKiB() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseKiB() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/tmsankaram/password-zen/pkg/policy"
+)
+
+// policyCmd represents the policy command
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Inspect password policies",
+	Long: `Inspect the built-in password policy presets and validate policy files.
+Policies are YAML or JSON documents used with 'analyze --policy' and 'generate --policy'.
+Start from a preset with 'password-zen policy show nist-800-63b > policy.yaml' and commit it next to your code.`,
+}
+
+var policyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the built-in policy presets",
+	Args:  cobra.NoArgs,
+	Run:   listPolicies,
+}
+
+var policyShowCmd = &cobra.Command{
+	Use:   "show <preset or file>",
+	Short: "Print a policy as YAML",
+	Args:  cobra.ExactArgs(1),
+	Run:   showPolicy,
+}
+
+func init() {
+	rootCmd.AddCommand(policyCmd)
+	policyCmd.AddCommand(policyListCmd)
+	policyCmd.AddCommand(policyShowCmd)
+}
+
+func listPolicies(cmd *cobra.Command, args []string) {
+	for _, name := range policy.Presets() {
+		preset, err := policy.Load(name)
+		if err != nil {
+			failf(cmd, exitIO, "Error loading preset %s: %v\n", name, err)
+			return
+		}
+		fmt.Printf("%-14s %s\n", name, preset.Description)
+	}
+}
+
+func showPolicy(cmd *cobra.Command, args []string) {
+	pol, err := policy.Load(args[0])
+	if err != nil {
+		failf(cmd, exitUsage, "Error loading policy: %v\n", err)
+		return
+	}
+
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(pol); err != nil {
+		failf(cmd, exitIO, "Error encoding policy: %v\n", err)
+		return
+	}
+	encoder.Close()
+}
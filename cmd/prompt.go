@@ -0,0 +1,90 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// promptPassword reads a password from the terminal with echo disabled. The
+// prompts are written to stderr so that stdout only carries the report. With
+// confirm set, the password must be entered twice.
+func promptPassword(confirm bool) (string, error) {
+	return promptSecret("password", confirm)
+}
+
+// promptSecret is promptPassword for a secret with another name, such as a
+// master passphrase
+func promptSecret(name string, confirm bool) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", errors.New("--prompt requires an interactive terminal, pipe passwords to stdin instead")
+	}
+
+	secret, err := readHidden(fd, strings.ToUpper(name[:1])+name[1:]+": ")
+	if err != nil {
+		return "", err
+	}
+	if secret == "" {
+		return "", errors.New("empty " + name)
+	}
+
+	if confirm {
+		again, err := readHidden(fd, "Confirm "+name+": ")
+		if err != nil {
+			return "", err
+		}
+		if again != secret {
+			return "", errors.New(name + "s do not match")
+		}
+	}
+	return secret, nil
+}
+
+func readHidden(fd int, prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	password, err := term.ReadPassword(fd)
+	// The newline typed by the user is not echoed either
+	fmt.Fprintln(os.Stderr)
+	if err == io.EOF {
+		return "", errors.New("no password entered")
+	}
+	return string(password), err
+}
+
+// stdinIsPiped reports whether stdin is redirected from a pipe or file
+// rather than connected to a terminal
+func stdinIsPiped() bool {
+	return !term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// readPassword prompts for a password at a terminal and otherwise reads the
+// first line of stdin. Only the line ending is removed from piped passwords.
+func readPassword(confirm bool) (string, error) {
+	return readSecret("password", confirm)
+}
+
+// readSecret is readPassword for a secret with another name
+func readSecret(name string, confirm bool) (string, error) {
+	if !stdinIsPiped() {
+		return promptSecret(name, confirm)
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	secret := strings.TrimRight(line, "\r\n")
+	if secret == "" {
+		return "", errors.New("empty " + name + " on stdin")
+	}
+	return secret, nil
+}
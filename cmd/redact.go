@@ -0,0 +1,162 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package cmd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/uniseg"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
+)
+
+// Modes of --show, how analysis reports identify the passwords
+const (
+	showNone   = "none"
+	showMasked = "masked"
+	showHash   = "hash"
+	showPlain  = "plain"
+)
+
+// showModes lists the modes of --show
+var showModes = []string{showNone, showMasked, showHash, showPlain}
+
+// minShowKeyLength is the length in bytes of the shortest --show-key-file key
+const minShowKeyLength = 16
+
+// showHashLength is the number of hex digits of the HMAC --show hash keeps
+const showHashLength = 16
+
+// quotedPattern matches the double-quoted strings of check messages, which
+// quote the parts of the password they found
+var quotedPattern = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+
+// passwordRedactor decides how reports show the passwords and the parts of
+// them quoted by the checks
+type passwordRedactor struct {
+	mode string
+	key  []byte
+}
+
+// newPasswordRedactor returns the redactor of a --show mode. The key of the
+// HMAC is only used, and required, with showHash.
+func newPasswordRedactor(mode string, key []byte) (*passwordRedactor, error) {
+	switch mode {
+	case showNone, showMasked, showPlain:
+	case showHash:
+		if len(key) < minShowKeyLength {
+			return nil, fmt.Errorf("--show hash needs a key of at least %d bytes", minShowKeyLength)
+		}
+	default:
+		return nil, fmt.Errorf("unknown --show mode %q, expected one of: %s", mode, strings.Join(showModes, ", "))
+	}
+	return &passwordRedactor{mode: mode, key: key}, nil
+}
+
+// show returns the form of the password reports show, "" with showNone.
+// showHash gives the first hex digits of its HMAC-SHA256, so that the same
+// password has the same hash in every report made with the key.
+func (r *passwordRedactor) show(password string) string {
+	switch r.mode {
+	case showMasked:
+		return maskPassword(password)
+	case showHash:
+		mac := hmac.New(sha256.New, r.key)
+		mac.Write([]byte(password))
+		return hex.EncodeToString(mac.Sum(nil))[:showHashLength]
+	case showPlain:
+		return password
+	}
+	return ""
+}
+
+// redact hides the parts of the password quoted by the checks of a result,
+// such as a banned word or keyboard walk it contains, and those the findings
+// and strength patterns carry, along with the rank of dictionary words and
+// the parsed dates. showMasked masks the parts like passwords, showNone and
+// showHash replace every character with *, showPlain keeps them.
+func (r *passwordRedactor) redact(result *analyzer.Result) {
+	if r.mode == showPlain {
+		return
+	}
+	for i := range result.Checks {
+		check := &result.Checks[i]
+		check.Message = r.redactQuoted(check.Message)
+		for j := range check.Findings {
+			finding := &check.Findings[j]
+			finding.Token = r.hide(finding.Token)
+			// The repeated unit and the context term are parts of the password,
+			// the details of walks and sequences are not
+			if finding.Pattern == "repetition" || finding.Pattern == "context" {
+				finding.Detail = r.hide(finding.Detail)
+			}
+		}
+	}
+	for i := range result.Strength.Patterns {
+		pattern := &result.Strength.Patterns[i]
+		pattern.Token = r.hide(pattern.Token)
+		pattern.Word = r.hide(pattern.Word)
+		// The unit of a repeat is part of the password, the layout of a walk
+		// and the alphabet of a sequence are not. The rank of a word and the
+		// parsed date give the hidden parts away.
+		if pattern.Pattern == "repeat" {
+			pattern.Detail = r.hide(pattern.Detail)
+		}
+		pattern.Rank, pattern.Date = 0, ""
+		pattern.Description = pattern.String()
+	}
+}
+
+func (r *passwordRedactor) redactQuoted(message string) string {
+	return quotedPattern.ReplaceAllStringFunc(message, func(quoted string) string {
+		fragment, err := strconv.Unquote(quoted)
+		if err != nil {
+			return quoted
+		}
+		return strconv.Quote(r.hide(fragment))
+	})
+}
+
+// hide returns a part of the password as redact shows it
+func (r *passwordRedactor) hide(fragment string) string {
+	if r.mode == showMasked {
+		return maskPassword(fragment)
+	}
+	return strings.Repeat("*", uniseg.GraphemeClusterCount(fragment))
+}
+
+// maskPassword keeps the first and last character of a password, as a user
+// perceives them, and replaces the others with *. Passwords of up to 4
+// characters are masked entirely, as their ends would give most of them away.
+func maskPassword(password string) string {
+	var chars []string
+	graphemes := uniseg.NewGraphemes(password)
+	for graphemes.Next() {
+		chars = append(chars, graphemes.Str())
+	}
+	if len(chars) <= 4 {
+		return strings.Repeat("*", len(chars))
+	}
+	return chars[0] + strings.Repeat("*", len(chars)-2) + chars[len(chars)-1]
+}
+
+// readShowKey returns the key of a --show-key-file, without the line break
+// ending the file
+func readShowKey(filepath string) ([]byte, error) {
+	if err := checkFileExists(filepath); err != nil {
+		return nil, err
+	}
+	key, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read file: %v", err)
+	}
+	return []byte(strings.TrimRight(string(key), "\r\n")), nil
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
+)
+
+var testShowKey = []byte("0123456789abcdef")
+
+func TestMaskPassword(t *testing.T) {
+	tests := []struct {
+		password string
+		want     string
+	}{
+		{"Summer2024!", "S*********!"},
+		{"abcde", "a***e"},
+		{"abcd", "****"},
+		{"", ""},
+		{"👍🏽secret👍🏽", "👍🏽******👍🏽"},
+	}
+
+	for _, tt := range tests {
+		if got := maskPassword(tt.password); got != tt.want {
+			t.Errorf("maskPassword(%q) = %q, want %q", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestPasswordRedactorShow(t *testing.T) {
+	show := func(mode string, key []byte, password string) string {
+		t.Helper()
+		redactor, err := newPasswordRedactor(mode, key)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return redactor.show(password)
+	}
+
+	if got := show(showNone, nil, "Summer2024!"); got != "" {
+		t.Errorf("none shows %q", got)
+	}
+	if got := show(showPlain, nil, "Summer2024!"); got != "Summer2024!" {
+		t.Errorf("plain shows %q", got)
+	}
+
+	// The same password and key give the same hash in every report
+	hash := show(showHash, testShowKey, "Summer2024!")
+	if len(hash) != showHashLength || strings.Contains(hash, "Summer") {
+		t.Errorf("hash shows %q", hash)
+	}
+	if again := show(showHash, testShowKey, "Summer2024!"); again != hash {
+		t.Errorf("hash is not stable: %q and %q", hash, again)
+	}
+	if other := show(showHash, []byte("fedcba9876543210"), "Summer2024!"); other == hash {
+		t.Errorf("hash does not depend on the key")
+	}
+	if other := show(showHash, testShowKey, "Summer2025!"); other == hash {
+		t.Errorf("hash does not depend on the password")
+	}
+}
+
+func TestPasswordRedactorInvalid(t *testing.T) {
+	if _, err := newPasswordRedactor("partial", nil); err == nil {
+		t.Errorf("Expected an error for an unknown mode")
+	}
+	if _, err := newPasswordRedactor(showHash, []byte("short")); err == nil {
+		t.Errorf("Expected an error for a short key")
+	}
+}
+
+func TestPasswordRedactorRedact(t *testing.T) {
+	criteria := testCriteria
+	criteria.ContextTerms = analyzer.ContextTerms("alice")
+	criteria.MaxRepetition = 3
+
+	tests := []struct {
+		mode  string
+		leaks bool
+		want  string
+	}{
+		{showNone, false, `"*****"`},
+		{showMasked, false, `"a***e"`},
+		{showHash, false, `"*****"`},
+		{showPlain, true, `"alice"`},
+	}
+
+	for _, tt := range tests {
+		for _, format := range []string{"text", "json", "ndjson"} {
+			t.Run(tt.mode+"/"+format, func(t *testing.T) {
+				redactor, err := newPasswordRedactor(tt.mode, testShowKey)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				result, _ := analyzer.Analyze("Alice-passwordxyxyxy", criteria)
+				result.Shown = redactor.show("Alice-passwordxyxyxy")
+				redactor.redact(&result)
+
+				var buf bytes.Buffer
+				records := []analysisRecord{{Index: 1, Result: result}}
+				if err := writeReport(&buf, format, records, summarizeRecords(records)); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				// JSON escapes the quotes of messages, the fields quote the fragments
+				report := strings.ReplaceAll(buf.String(), `\"`, `"`)
+				report = strings.ReplaceAll(report, `"password":`, "")
+				leaks := false
+				for _, fragment := range []string{`"password"`, `"alice"`, `"xyxyxy"`, `"xy"`} {
+					leaks = leaks || strings.Contains(report, fragment)
+				}
+				if leaks != tt.leaks {
+					t.Errorf("Report leaks the password: %v, want %v\n%s", leaks, tt.leaks, report)
+				}
+				if !strings.Contains(report, tt.want) {
+					t.Errorf("Report lacks %s:\n%s", tt.want, report)
+				}
+			})
+		}
+	}
+}
+
+func TestPasswordRedactorRedactPatterns(t *testing.T) {
+	for _, mode := range []string{showNone, showMasked, showHash} {
+		for _, format := range []string{"text", "json"} {
+			t.Run(mode+"/"+format, func(t *testing.T) {
+				redactor, err := newPasswordRedactor(mode, testShowKey)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				result, _ := analyzer.Analyze("Monkey14.05.1990", testCriteria)
+				redactor.redact(&result)
+				if len(result.Strength.Patterns) != 2 {
+					t.Fatalf("Patterns = %v, want a dictionary word and a date", result.Strength.Patterns)
+				}
+
+				var buf bytes.Buffer
+				records := []analysisRecord{{Index: 1, Result: result}}
+				if err := writeReport(&buf, format, records, summarizeRecords(records)); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				for _, leak := range []string{"onke", "monkey", "05.19", "1990-05-14", "rank"} {
+					if strings.Contains(strings.ToLower(buf.String()), leak) {
+						t.Errorf("Report contains %q:\n%s", leak, buf.String())
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestWriteCSVReportPassword(t *testing.T) {
+	records, summary := testRecords()
+	for i := range records {
+		records[i].Shown = maskPassword("kX9#vQ2mLp7z")
+	}
+
+	var buf bytes.Buffer
+	if err := writeReport(&buf, "csv", records, summary); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.HasPrefix(lines[0], "index,password,passed,") || !strings.HasPrefix(lines[1], "1,k**********z,false,") {
+		t.Errorf("Unexpected CSV report:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,437 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
+)
+
+// reportSchemaVersion is bumped whenever a field of the report schema changes
+// meaning or is removed. Adding fields does not change the version.
+const reportSchemaVersion = 1
+
+// reportFormats lists the supported analysis report formats
+var reportFormats = []string{"text", "json", "ndjson", "csv"}
+
+// analysisRecord is the analysis result of a single password
+type analysisRecord struct {
+	Index int `json:"index"`
+	analyzer.Result
+}
+
+// analysisSummary holds the counts over all analysed passwords
+type analysisSummary struct {
+	Total  int `json:"total"`
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+
+	// Reuse holds the groups of reused passwords found with --reuse, nil
+	// without it. Each format writes them in its own place.
+	Reuse []analyzer.ReuseGroup `json:"-"`
+}
+
+func summarizeRecords(records []analysisRecord) analysisSummary {
+	var summary analysisSummary
+	for _, record := range records {
+		summary.add(record)
+	}
+	return summary
+}
+
+// add counts a record into the summary
+func (s *analysisSummary) add(record analysisRecord) {
+	s.Total++
+	if record.Passed {
+		s.Passed++
+	} else {
+		s.Failed++
+	}
+}
+
+func isReportFormat(format string) bool {
+	for _, f := range reportFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// reportWriter writes a report one record at a time, so that reports of any
+// size are written in constant memory
+type reportWriter interface {
+	writeRecord(record analysisRecord) error
+	// finish writes the summary and flushes the report
+	finish(summary analysisSummary) error
+}
+
+// newReportWriter returns a writer for the given format
+func newReportWriter(w io.Writer, format string) (reportWriter, error) {
+	switch format {
+	case "text":
+		return &textReportWriter{w: w}, nil
+	case "json":
+		return &jsonReportWriter{w: w}, nil
+	case "ndjson":
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		return &ndjsonReportWriter{encoder: encoder}, nil
+	case "csv":
+		return &csvReportWriter{w: w, writer: csv.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+// writeReport writes the records and summary to w in the given format
+func writeReport(w io.Writer, format string, records []analysisRecord, summary analysisSummary) error {
+	report, err := newReportWriter(w, format)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := report.writeRecord(record); err != nil {
+			return err
+		}
+	}
+	return report.finish(summary)
+}
+
+// formatTextRecord renders a record as text, with colors when colored is set
+func formatTextRecord(record analysisRecord, colored bool) string {
+	pass, fail := "✓", "✗"
+	status := "STRONG ✓"
+	if colored {
+		pass, fail = greenCheck("✓"), redCross("✗")
+		status = greenText("STRONG") + " " + greenCheck("✓")
+	}
+	if !record.Passed {
+		status = "WEAK ✗"
+		if colored {
+			status = redText("WEAK") + " " + redCross("✗")
+		}
+	}
+
+	label := "Password"
+	if colored {
+		label = cyanText(label)
+	}
+
+	heading := fmt.Sprintf("%s %d", label, record.Index)
+	if record.Shown != "" {
+		heading += " " + strconv.Quote(record.Shown)
+	}
+	if record.Entry != nil {
+		heading += fmt.Sprintf(" on line %d", record.Entry.Line)
+		if entry := record.Entry.String(); entry != "" {
+			heading += ", " + entry
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s\n", heading, status)
+	for _, check := range record.Checks {
+		mark := pass
+		if !check.Passed {
+			mark = fail
+		}
+		fmt.Fprintf(&b, "  %s %s\n", mark, check.Message)
+	}
+	for _, line := range formatStrengthReport(record.Strength) {
+		fmt.Fprintf(&b, "%s\n", line)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// reuseKindLabels describe the kinds of reuse groups in text reports
+var reuseKindLabels = map[string]string{
+	analyzer.ReuseExact:   "Exact duplicates",
+	analyzer.ReuseCase:    "Case-insensitive duplicates",
+	analyzer.ReuseSimilar: "Similar passwords",
+}
+
+// formatReuseReport renders the reuse groups as text, with colors when colored
+// is set. The passwords and base words are only shown when present, which
+// they are with --reveal.
+func formatReuseReport(groups []analyzer.ReuseGroup, colored bool) string {
+	pass, fail := "✓", "✗"
+	if colored {
+		pass, fail = greenCheck("✓"), redCross("✗")
+	}
+
+	var b strings.Builder
+	if len(groups) == 0 {
+		fmt.Fprintf(&b, "Password reuse:\n  %s No reused passwords\n\n", pass)
+		return b.String()
+	}
+	fmt.Fprintf(&b, "Password reuse: %d groups\n", len(groups))
+	for _, group := range groups {
+		lines := make([]string, len(group.Lines))
+		for i, line := range group.Lines {
+			lines[i] = strconv.Itoa(line)
+		}
+		fmt.Fprintf(&b, "  %s %s on lines %s", fail, reuseKindLabels[group.Kind], strings.Join(lines, ", "))
+		if group.BaseWord != "" {
+			fmt.Fprintf(&b, " (base word %q)", group.BaseWord)
+		}
+		b.WriteString("\n")
+		if len(group.Passwords) > 0 {
+			fmt.Fprintf(&b, "    %s\n", quoteAll(group.Passwords))
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// quoteAll quotes the values and joins them with commas
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// formatStrengthReport returns the report lines explaining a strength estimate
+func formatStrengthReport(report analyzer.Strength) []string {
+	lines := []string{fmt.Sprintf("    Estimated guesses: %.3g (%.1f bits of entropy)", report.Guesses, report.EntropyBits)}
+	for _, crackTime := range report.CrackTimes {
+		lines = append(lines, fmt.Sprintf("    Crack time, %s: %s", crackTime.Scenario, crackTime.Display))
+	}
+	for _, pattern := range report.Patterns {
+		lines = append(lines, "    Pattern: "+pattern.Description)
+	}
+	return lines
+}
+
+type textReportWriter struct {
+	w io.Writer
+}
+
+func (t *textReportWriter) writeRecord(record analysisRecord) error {
+	_, err := io.WriteString(t.w, formatTextRecord(record, false))
+	return err
+}
+
+func (t *textReportWriter) finish(summary analysisSummary) error {
+	if summary.Reuse != nil {
+		if _, err := io.WriteString(t.w, formatReuseReport(summary.Reuse, false)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(t.w, "Summary: %d/%d passwords meet all criteria\n", summary.Passed, summary.Total)
+	return err
+}
+
+// jsonReport is the envelope of the json format
+type jsonReport struct {
+	SchemaVersion int                   `json:"schema_version"`
+	Results       []analysisRecord      `json:"results"`
+	Reuse         []analyzer.ReuseGroup `json:"reuse,omitempty"`
+	Summary       analysisSummary       `json:"summary"`
+}
+
+// jsonReportWriter writes the jsonReport envelope piece by piece, with the
+// same layout json.Encoder gives it with a two space indent
+type jsonReportWriter struct {
+	w       io.Writer
+	records int
+}
+
+func (j *jsonReportWriter) writeRecord(record analysisRecord) error {
+	separator := ",\n    "
+	if j.records == 0 {
+		separator = fmt.Sprintf("{\n  \"schema_version\": %d,\n  \"results\": [\n    ", reportSchemaVersion)
+	}
+	j.records++
+
+	data, err := marshalIndent(record, "    ")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(j.w, separator+data)
+	return err
+}
+
+func (j *jsonReportWriter) finish(summary analysisSummary) error {
+	results := "\n  ],"
+	if j.records == 0 {
+		results = fmt.Sprintf("{\n  \"schema_version\": %d,\n  \"results\": [],", reportSchemaVersion)
+	}
+
+	if summary.Reuse != nil {
+		data, err := marshalIndent(summary.Reuse, "  ")
+		if err != nil {
+			return err
+		}
+		results += "\n  \"reuse\": " + data + ","
+	}
+
+	data, err := marshalIndent(summary, "  ")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(j.w, results+"\n  \"summary\": "+data+"\n}\n")
+	return err
+}
+
+// marshalIndent encodes v as indented JSON without escaping HTML characters
+func marshalIndent(v any, prefix string) (string, error) {
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent(prefix, "  ")
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// ndjsonResult, ndjsonReuse and ndjsonSummary are the lines of the ndjson
+// format
+type ndjsonResult struct {
+	Type string `json:"type"`
+	analysisRecord
+}
+
+type ndjsonReuse struct {
+	Type string `json:"type"`
+	analyzer.ReuseGroup
+}
+
+type ndjsonSummary struct {
+	Type          string `json:"type"`
+	SchemaVersion int    `json:"schema_version"`
+	analysisSummary
+}
+
+type ndjsonReportWriter struct {
+	encoder *json.Encoder
+}
+
+func (n *ndjsonReportWriter) writeRecord(record analysisRecord) error {
+	return n.encoder.Encode(ndjsonResult{Type: "result", analysisRecord: record})
+}
+
+func (n *ndjsonReportWriter) finish(summary analysisSummary) error {
+	for _, group := range summary.Reuse {
+		if err := n.encoder.Encode(ndjsonReuse{Type: "reuse", ReuseGroup: group}); err != nil {
+			return err
+		}
+	}
+	return n.encoder.Encode(ndjsonSummary{Type: "summary", SchemaVersion: reportSchemaVersion, analysisSummary: summary})
+}
+
+// csvReportWriter writes one row per password with a column per check. The
+// reuse groups and the summary follow as comment lines starting with '#'.
+type csvReportWriter struct {
+	w            io.Writer
+	writer       *csv.Writer
+	header       bool
+	withPassword bool
+	withEntry    bool
+	withBreach   bool
+}
+
+// writeHeader writes the header row. All records run the same checks, so the
+// first one defines the columns.
+func (c *csvReportWriter) writeHeader(first *analysisRecord) error {
+	c.header = true
+
+	header := []string{"index"}
+	c.withPassword = first != nil && first.Shown != ""
+	if c.withPassword {
+		header = append(header, "password")
+	}
+	c.withEntry = first != nil && first.Entry != nil
+	if c.withEntry {
+		header = append(header, "line", "title", "url", "username")
+	}
+	header = append(header, "passed", "length")
+	if first != nil {
+		for _, check := range first.Checks {
+			header = append(header, "check_"+check.Name)
+		}
+	}
+	header = append(header, "score", "entropy_bits", "guesses")
+	c.withBreach = first != nil && first.BreachCount != nil
+	if c.withBreach {
+		header = append(header, "breach_count")
+	}
+	return c.writer.Write(header)
+}
+
+func (c *csvReportWriter) writeRecord(record analysisRecord) error {
+	if !c.header {
+		if err := c.writeHeader(&record); err != nil {
+			return err
+		}
+	}
+
+	row := []string{strconv.Itoa(record.Index)}
+	if c.withPassword {
+		row = append(row, record.Shown)
+	}
+	if c.withEntry {
+		var entry analyzer.Entry
+		if record.Entry != nil {
+			entry = *record.Entry
+		}
+		row = append(row, strconv.Itoa(entry.Line), entry.Title, entry.URL, entry.Username)
+	}
+	row = append(row, strconv.FormatBool(record.Passed), strconv.Itoa(record.Length))
+	for _, check := range record.Checks {
+		row = append(row, strconv.FormatBool(check.Passed))
+	}
+	row = append(row,
+		strconv.Itoa(record.Strength.Score),
+		strconv.FormatFloat(record.Strength.EntropyBits, 'f', 2, 64),
+		strconv.FormatFloat(record.Strength.Guesses, 'g', -1, 64),
+	)
+	if c.withBreach {
+		row = append(row, strconv.Itoa(*record.BreachCount))
+	}
+	return c.writer.Write(row)
+}
+
+func (c *csvReportWriter) finish(summary analysisSummary) error {
+	if !c.header {
+		if err := c.writeHeader(nil); err != nil {
+			return err
+		}
+	}
+
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		return err
+	}
+
+	for _, group := range summary.Reuse {
+		lines := make([]string, len(group.Lines))
+		for i, line := range group.Lines {
+			lines[i] = strconv.Itoa(line)
+		}
+		comment := fmt.Sprintf("# reuse kind=%s lines=%s", group.Kind, strings.Join(lines, ";"))
+		if group.BaseWord != "" {
+			comment += " base_word=" + strconv.Quote(group.BaseWord)
+		}
+		if len(group.Passwords) > 0 {
+			comment += " passwords=" + strings.ReplaceAll(quoteAll(group.Passwords), ", ", ",")
+		}
+		if _, err := fmt.Fprintln(c.w, comment); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(c.w, "# schema_version=%d total=%d passed=%d failed=%d\n", reportSchemaVersion, summary.Total, summary.Passed, summary.Failed)
+	return err
+}
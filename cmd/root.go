@@ -22,15 +22,40 @@ Features:
 • Colorful output with animations (can be disabled)
 • Cross-platform support (Windows, Linux, macOS)
 
-Use 'password-zen <command> --help' for detailed command information.`,
+Use 'password-zen <command> --help' for detailed command information.
+
+Exit codes:
+  0  success, all analyzed passwords pass
+  1  passwords fail the criteria or policy
+  2  usage or input error
+  3  I/O error`,
 	Version: version.Short(),
 }
 
+// Exit codes of the process
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitUsage  = 2
+	exitIO     = 3
+)
+
+// exitCode is the exit code set by the command that ran
+var exitCode = exitOK
+
+// failf prints an error message and sets the exit code of the process
+func failf(cmd *cobra.Command, code int, format string, args ...any) {
+	cmd.PrintErrf(format, args...)
+	exitCode = code
+}
+
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		// Cobra reports unknown commands and invalid flags
+		os.Exit(exitUsage)
 	}
+	os.Exit(exitCode)
 }
 
 func init() {
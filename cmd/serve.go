@@ -0,0 +1,512 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
+	"github.com/tmsankaram/password-zen/pkg/breach"
+	"github.com/tmsankaram/password-zen/pkg/generator"
+	"github.com/tmsankaram/password-zen/pkg/policy"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve generation and analysis over a local HTTP API",
+	Long: `Serve password generation and analysis over a JSON HTTP API, e.g. as a sidecar of a signup service.
+
+Endpoints:
+  POST /v1/generate        generate a password, the body mirrors the generate flags
+  POST /v1/analyze         analyze one password, the body mirrors the analyze flags
+  POST /v1/analyze/batch   analyze up to --max-batch passwords, returns the json report
+  GET  /healthz            liveness
+  GET  /readyz             readiness, fails while starting and shutting down
+
+Analyzed passwords may be up to 256 characters long, counted in code points after NFKC normalization like the length check of analyze.
+
+Requests may name a built-in policy preset but not policy files.
+Access logs are written to stderr as JSON lines and never contain request bodies.
+The server listens on localhost by default and has no authentication, put it behind your own proxy before exposing it.`,
+	Args: cobra.NoArgs,
+	Run:  runServer,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().String("addr", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.Flags().Int64("max-body-bytes", 1<<20, "Maximum request body size in bytes")
+	serveCmd.Flags().Int("max-batch", 1000, "Maximum number of passwords in a batch request")
+	serveCmd.Flags().String("breach-db", "", "Breach database checked by every analysis, see 'analyze --breach-db'")
+	serveCmd.Flags().Duration("shutdown-timeout", 10*time.Second, "Time allowed for in-flight requests to finish on shutdown")
+}
+
+func runServer(cmd *cobra.Command, args []string) {
+	addr, _ := cmd.Flags().GetString("addr")
+	maxBodyBytes, _ := cmd.Flags().GetInt64("max-body-bytes")
+	maxBatch, _ := cmd.Flags().GetInt("max-batch")
+	breachDBPath, _ := cmd.Flags().GetString("breach-db")
+	shutdownTimeout, _ := cmd.Flags().GetDuration("shutdown-timeout")
+
+	if maxBodyBytes <= 0 || maxBatch <= 0 {
+		failf(cmd, exitUsage, "Error: --max-body-bytes and --max-batch must be greater than 0\n")
+		return
+	}
+
+	server := &apiServer{
+		maxBodyBytes: maxBodyBytes,
+		maxBatch:     maxBatch,
+		logger:       slog.New(slog.NewJSONHandler(cmd.ErrOrStderr(), nil)),
+	}
+
+	if breachDBPath != "" {
+		if err := checkFileExists(breachDBPath); err != nil {
+			failf(cmd, exitIO, "Error opening breach database: %v\n", err)
+			return
+		}
+		db, err := breach.Open(breachDBPath)
+		if err != nil {
+			failf(cmd, exitIO, "Error opening breach database: %v\n", err)
+			return
+		}
+		defer db.Close()
+		server.breach = db
+	}
+
+	httpServer := &http.Server{
+		Handler:           server.routes(),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      60 * time.Second,
+		IdleTimeout:       120 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		failf(cmd, exitIO, "Error listening: %v\n", err)
+		return
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- httpServer.Serve(listener)
+	}()
+	server.logger.Info("listening", "addr", listener.Addr().String())
+
+	// Load the embedded dictionaries before reporting ready
+	analyzer.EstimateStrength("")
+	server.ready.Store(true)
+
+	select {
+	case err := <-errs:
+		failf(cmd, exitIO, "Error serving: %v\n", err)
+		return
+	case <-ctx.Done():
+	}
+
+	server.ready.Store(false)
+	server.logger.Info("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		failf(cmd, exitIO, "Error shutting down: %v\n", err)
+	}
+}
+
+// apiServer serves the HTTP API
+type apiServer struct {
+	maxBodyBytes int64
+	maxBatch     int
+	breach       analyzer.BreachChecker
+	logger       *slog.Logger
+	ready        atomic.Bool
+}
+
+// routes returns the API handler wrapped in the access log
+func (s *apiServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/generate", s.handleGenerate)
+	mux.HandleFunc("POST /v1/analyze", s.handleAnalyze)
+	mux.HandleFunc("POST /v1/analyze/batch", s.handleAnalyzeBatch)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !s.ready.Load() {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "not ready"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+	})
+	return s.accessLog(mux)
+}
+
+// maxAPIPasswordLength is the longest password the API generates, as for the generate command
+const maxAPIPasswordLength = 128
+
+// maxAPIAnalyzeLength is the longest password the API analyzes in characters,
+// which bounds the work a single request can cause
+const maxAPIAnalyzeLength = 256
+
+// analyzeLengthExceeded reports whether a password is longer than
+// maxAPIAnalyzeLength, counting the code points of its normalized form as the
+// length check does
+func analyzeLengthExceeded(password string) bool {
+	return utf8.RuneCountInString(analyzer.Normalize(password)) > maxAPIAnalyzeLength
+}
+
+// generateRequest mirrors the generate flags. Unset fields take the flag defaults.
+type generateRequest struct {
+	Length           *int   `json:"length"`
+	IncludeSymbols   bool   `json:"include_symbols"`
+	IncludeDigits    *bool  `json:"include_digits"`
+	ExcludeAmbiguous bool   `json:"exclude_ambiguous"`
+	Charset          string `json:"charset"`
+	MinLower         *int   `json:"min_lower"`
+	MinUpper         *int   `json:"min_upper"`
+	MinDigits        *int   `json:"min_digits"`
+	MinSymbols       *int   `json:"min_symbols"`
+	Policy           string `json:"policy"`
+}
+
+type generateResponse struct {
+	Password string `json:"password"`
+}
+
+func (s *apiServer) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	var req generateRequest
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+
+	length := valueOr(req.Length, 12)
+	includeDigits := valueOr(req.IncludeDigits, true)
+	minimums := map[string]*int{
+		"lower":   req.MinLower,
+		"upper":   req.MinUpper,
+		"digits":  req.MinDigits,
+		"symbols": req.MinSymbols,
+	}
+
+	if req.Policy != "" {
+		if req.Charset != "" || req.MinLower != nil || req.MinUpper != nil || req.MinDigits != nil || req.MinSymbols != nil {
+			writeError(w, http.StatusBadRequest, "policy cannot be combined with charset or min_* fields")
+			return
+		}
+		pol, ok := s.loadPreset(w, req.Policy)
+		if !ok {
+			return
+		}
+		length = policyLength(pol, length, req.Length != nil)
+		if length > maxAPIPasswordLength {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("length must not exceed %d", maxAPIPasswordLength))
+			return
+		}
+		password, err := pol.Generate(policy.GenerateOptions{
+			Length:           length,
+			IncludeDigits:    includeDigits,
+			IncludeSymbols:   req.IncludeSymbols,
+			ExcludeAmbiguous: req.ExcludeAmbiguous,
+			Breach:           s.breach,
+		})
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, generateResponse{Password: password})
+		return
+	}
+
+	if length <= 0 || length > maxAPIPasswordLength {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("length must be between 1 and %d", maxAPIPasswordLength))
+		return
+	}
+
+	opts := generator.Options{
+		Length:           length,
+		IncludeDigits:    includeDigits,
+		IncludeSymbols:   req.IncludeSymbols,
+		ExcludeAmbiguous: req.ExcludeAmbiguous,
+		Charset:          req.Charset,
+	}
+	values := make(map[string]int)
+	explicit := make(map[string]bool)
+	for name, value := range minimums {
+		values[name] = valueOr(value, 1)
+		explicit[name] = value != nil
+	}
+	if err := applyClassMinimums(&opts, values, explicit, "min_%s"); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	password, err := generator.Generate(opts)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, generateResponse{Password: password})
+}
+
+// analyzeOptions mirrors the analyze flags. Unset fields take the flag defaults.
+type analyzeOptions struct {
+	MinLength        *int   `json:"min_length"`
+	RequireSymbols   *bool  `json:"require_symbols"`
+	RequireDigits    *bool  `json:"require_digits"`
+	RequireUppercase *bool  `json:"require_uppercase"`
+	RequireLowercase *bool  `json:"require_lowercase"`
+	MinScore         *int   `json:"min_score"`
+	Policy           string `json:"policy"`
+}
+
+type analyzeRequest struct {
+	Password string `json:"password"`
+	analyzeOptions
+}
+
+type analyzeBatchRequest struct {
+	Passwords []string `json:"passwords"`
+	analyzeOptions
+}
+
+func (s *apiServer) handleAnalyze(w http.ResponseWriter, r *http.Request) {
+	var req analyzeRequest
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Password == "" {
+		writeError(w, http.StatusBadRequest, "password is required")
+		return
+	}
+	if analyzeLengthExceeded(req.Password) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("password must not exceed %d characters", maxAPIAnalyzeLength))
+		return
+	}
+
+	criteria, ok := s.criteria(w, req.analyzeOptions)
+	if !ok {
+		return
+	}
+
+	result, err := analyzer.Analyze(req.Password, criteria)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "analysis failed")
+		s.logger.Error("analysis failed", "error", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *apiServer) handleAnalyzeBatch(w http.ResponseWriter, r *http.Request) {
+	var req analyzeBatchRequest
+	if !s.decodeJSON(w, r, &req) {
+		return
+	}
+	if len(req.Passwords) == 0 {
+		writeError(w, http.StatusBadRequest, "passwords is required")
+		return
+	}
+	if len(req.Passwords) > s.maxBatch {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("batch exceeds %d passwords", s.maxBatch))
+		return
+	}
+	for i, password := range req.Passwords {
+		if analyzeLengthExceeded(password) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("password %d must not exceed %d characters", i+1, maxAPIAnalyzeLength))
+			return
+		}
+	}
+
+	criteria, ok := s.criteria(w, req.analyzeOptions)
+	if !ok {
+		return
+	}
+
+	records := make([]analysisRecord, 0, len(req.Passwords))
+	for i, password := range req.Passwords {
+		result, err := analyzer.Analyze(password, criteria)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "analysis failed")
+			s.logger.Error("analysis failed", "error", err)
+			return
+		}
+		records = append(records, analysisRecord{Index: i + 1, Result: result})
+	}
+
+	// The batch response is the json report of the analyze command
+	var buf bytes.Buffer
+	if err := writeReport(&buf, "json", records, summarizeRecords(records)); err != nil {
+		writeError(w, http.StatusInternalServerError, "cannot encode report")
+		s.logger.Error("cannot encode report", "error", err)
+		return
+	}
+	writeBody(w, http.StatusOK, buf.Bytes())
+}
+
+// criteria converts the analyze options of a request, writing an error
+// response when they are invalid
+func (s *apiServer) criteria(w http.ResponseWriter, opts analyzeOptions) (analyzer.Criteria, bool) {
+	var criteria analyzer.Criteria
+	if opts.Policy != "" {
+		if opts.MinLength != nil || opts.RequireSymbols != nil || opts.RequireDigits != nil || opts.RequireUppercase != nil || opts.RequireLowercase != nil || opts.MinScore != nil {
+			writeError(w, http.StatusBadRequest, "policy cannot be combined with min_length, require_* or min_score")
+			return criteria, false
+		}
+		pol, ok := s.loadPreset(w, opts.Policy)
+		if !ok {
+			return criteria, false
+		}
+		criteria = pol.Criteria()
+	} else {
+		criteria = flagCriteria(
+			valueOr(opts.MinLength, 8),
+			valueOr(opts.MinScore, 3),
+			valueOr(opts.RequireSymbols, false),
+			valueOr(opts.RequireDigits, true),
+			valueOr(opts.RequireUppercase, true),
+			valueOr(opts.RequireLowercase, true),
+		)
+	}
+
+	if criteria.MinScore < 0 || criteria.MinScore > 4 {
+		writeError(w, http.StatusBadRequest, "min_score must be between 0 and 4")
+		return criteria, false
+	}
+	criteria.Breach = s.breach
+	return criteria, true
+}
+
+// loadPreset loads a built-in policy preset. Policy files are not available
+// over the API so that clients cannot make the server read arbitrary files.
+func (s *apiServer) loadPreset(w http.ResponseWriter, name string) (policy.Policy, bool) {
+	for _, preset := range policy.Presets() {
+		if preset == name {
+			pol, err := policy.Load(name)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return pol, false
+			}
+			return pol, true
+		}
+	}
+	writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown policy preset %q", name))
+	return policy.Policy{}, false
+}
+
+// decodeJSON decodes a size limited JSON body into v, writing an error
+// response when it is invalid
+func (s *apiServer) decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.maxBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if err == nil && decoder.More() {
+		err = errors.New("body must contain a single JSON object")
+	}
+
+	var tooLarge *http.MaxBytesError
+	switch {
+	case err == nil:
+		return true
+	case errors.As(err, &tooLarge):
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("body exceeds %d bytes", s.maxBodyBytes))
+	case errors.Is(err, io.EOF):
+		writeError(w, http.StatusBadRequest, "body is empty")
+	default:
+		// Decoder errors describe the offending field or offset, never its value
+		writeError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+	}
+	return false
+}
+
+// statusRecorder captures the status and size of a response for the access log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += n
+	return n, err
+}
+
+// accessLog logs one line per request. Only the path is logged, request and
+// response bodies are not, as they contain passwords.
+func (s *apiServer) accessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		s.logger.Info("request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.status,
+			"bytes", recorder.bytes,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"remote", r.RemoteAddr,
+		)
+	})
+}
+
+// writeJSON encodes v before writing the status, so that a value that cannot
+// be encoded gets a 500 response instead of a truncated body
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		status = http.StatusInternalServerError
+		buf.Reset()
+		buf.WriteString(`{"error":"cannot encode response"}` + "\n")
+	}
+	writeBody(w, status, buf.Bytes())
+}
+
+// writeBody writes an encoded JSON response
+func writeBody(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// valueOr returns *v, or def when v is nil
+func valueOr[T any](v *T, def T) T {
+	if v == nil {
+		return def
+	}
+	return *v
+}
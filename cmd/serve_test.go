@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestServer(logs io.Writer) *httptest.Server {
+	server := &apiServer{
+		maxBodyBytes: 1024,
+		maxBatch:     2,
+		logger:       slog.New(slog.NewJSONHandler(logs, nil)),
+	}
+	server.ready.Store(true)
+	return httptest.NewServer(server.routes())
+}
+
+func TestServeEndpoints(t *testing.T) {
+	var logs bytes.Buffer
+	server := newTestServer(&logs)
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"Health", "GET", "/healthz", "", http.StatusOK, `"ok"`},
+		{"Readiness", "GET", "/readyz", "", http.StatusOK, `"ready"`},
+		{"Generate", "POST", "/v1/generate", `{"length": 20, "include_symbols": true, "min_symbols": 3}`, http.StatusOK, `"password"`},
+		{"Generate for preset", "POST", "/v1/generate", `{"policy": "pci-dss"}`, http.StatusOK, `"password"`},
+		{"Generate minimum without class", "POST", "/v1/generate", `{"min_symbols": 2}`, http.StatusBadRequest, "min_symbols requires symbols"},
+		{"Generate short", "POST", "/v1/generate", `{"length": 2}`, http.StatusOK, `"password"`},
+		{"Generate too long", "POST", "/v1/generate", `{"length": 1000}`, http.StatusBadRequest, "length must be between"},
+		{"Analyze", "POST", "/v1/analyze", `{"password": "Password1"}`, http.StatusOK, `"passed":false`},
+		{"Analyze with preset", "POST", "/v1/analyze", `{"password": "kX9#vQ2mLp7zR4", "policy": "cis"}`, http.StatusOK, `"passed":true`},
+		{"Analyze policy file is rejected", "POST", "/v1/analyze", `{"password": "x", "policy": "/etc/passwd"}`, http.StatusBadRequest, "unknown policy preset"},
+		{"Analyze policy with flags", "POST", "/v1/analyze", `{"password": "x", "policy": "cis", "min_score": 1}`, http.StatusBadRequest, "cannot be combined"},
+		{"Analyze unknown field", "POST", "/v1/analyze", `{"pasword": "x"}`, http.StatusBadRequest, "unknown field"},
+		{"Analyze empty body", "POST", "/v1/analyze", ``, http.StatusBadRequest, "body is empty"},
+		{"Analyze body too large", "POST", "/v1/analyze", `{"password": "` + strings.Repeat("a", 2048) + `"}`, http.StatusRequestEntityTooLarge, "body exceeds"},
+		{"Analyze too long", "POST", "/v1/analyze", `{"password": "` + strings.Repeat("ä", maxAPIAnalyzeLength+1) + `"}`, http.StatusBadRequest, "must not exceed 256 characters"},
+		{"Analyze too long after normalization", "POST", "/v1/analyze", `{"password": "` + strings.Repeat("ﷺ", 15) + `"}`, http.StatusBadRequest, "must not exceed 256 characters"},
+		{"Analyze wrong method", "GET", "/v1/analyze", "", http.StatusMethodNotAllowed, ""},
+		{"Batch", "POST", "/v1/analyze/batch", `{"passwords": ["Password1", "kX9#vQ2mLp7z"]}`, http.StatusOK, "\"summary\": {\n    \"total\": 2,\n    \"passed\": 1,\n    \"failed\": 1\n  }"},
+		{"Batch too long", "POST", "/v1/analyze/batch", `{"passwords": ["Password1", "` + strings.Repeat("a", maxAPIAnalyzeLength+1) + `"]}`, http.StatusBadRequest, "password 2 must not exceed"},
+		{"Batch too large", "POST", "/v1/analyze/batch", `{"passwords": ["a", "b", "c"]}`, http.StatusRequestEntityTooLarge, "batch exceeds 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Status = %d, want %d: %s", resp.StatusCode, tt.wantStatus, body)
+			}
+			if !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("Body should contain %q, got %s", tt.wantBody, body)
+			}
+		})
+	}
+
+	// Access logs must never contain password material
+	for _, secret := range []string{"Password1", "kX9#vQ2mLp7z", `"password"`} {
+		if strings.Contains(logs.String(), secret) {
+			t.Errorf("Access log contains %q:\n%s", secret, logs.String())
+		}
+	}
+	if !strings.Contains(logs.String(), `"path":"/v1/analyze"`) {
+		t.Errorf("Access log is missing requests:\n%s", logs.String())
+	}
+}
+
+func TestServeGeneratedPasswordMatchesRequest(t *testing.T) {
+	server := newTestServer(io.Discard)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/v1/generate", "application/json", strings.NewReader(`{"length": 16, "charset": "ab12", "min_digits": 4}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var generated generateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&generated); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(generated.Password) != 16 || strings.Trim(generated.Password, "ab12") != "" {
+		t.Errorf("Unexpected password %q", generated.Password)
+	}
+	if digits := strings.Count(generated.Password, "1") + strings.Count(generated.Password, "2"); digits < 4 {
+		t.Errorf("Password %q has %d digits, want at least 4", generated.Password, digits)
+	}
+}
+
+func TestServeNotReady(t *testing.T) {
+	server := &apiServer{logger: slog.New(slog.NewJSONHandler(io.Discard, nil))}
+	recorder := httptest.NewRecorder()
+	server.routes().ServeHTTP(recorder, httptest.NewRequest("GET", "/readyz", nil))
+
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Status = %d, want %d", recorder.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestWriteJSONError(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	writeJSON(recorder, http.StatusOK, map[string]float64{"guesses": math.Inf(1)})
+
+	if recorder.Code != http.StatusInternalServerError || !strings.Contains(recorder.Body.String(), `"error"`) {
+		t.Errorf("Unexpected response %d: %s", recorder.Code, recorder.Body.String())
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
+)
+
+// statsFormats lists the formats of the --stats report
+var statsFormats = []string{"text", "json", "html"}
+
+// statsBarWidth is the width of the longest bar of the text report
+const statsBarWidth = 30
+
+// statsReport is the envelope of the json format of --stats
+type statsReport struct {
+	SchemaVersion int                  `json:"schema_version"`
+	Stats         analyzer.StatsReport `json:"stats"`
+	Summary       analysisSummary      `json:"summary"`
+}
+
+// statsRow is a bar of a section of the text and html reports
+type statsRow struct {
+	Label   string
+	Count   int
+	Percent float64
+	// Width is the length of the bar relative to the longest of its section
+	Width float64
+}
+
+// statsSection is a titled list of bars
+type statsSection struct {
+	Title string
+	Rows  []statsRow
+}
+
+// statsSections returns the sections of the text and html reports
+func statsSections(report analyzer.StatsReport) []statsSection {
+	section := func(title string, labels []string, counts []int) statsSection {
+		most := 0
+		for _, count := range counts {
+			most = max(most, count)
+		}
+		s := statsSection{Title: title}
+		for i, count := range counts {
+			row := statsRow{Label: labels[i], Count: count}
+			if report.Total > 0 {
+				row.Percent = float64(count) * 100 / float64(report.Total)
+			}
+			if most > 0 {
+				row.Width = float64(count) * 100 / float64(most)
+			}
+			s.Rows = append(s.Rows, row)
+		}
+		return s
+	}
+	values := func(title string, counts []analyzer.StatsCount) statsSection {
+		labels := make([]string, len(counts))
+		numbers := make([]int, len(counts))
+		for i, count := range counts {
+			labels[i], numbers[i] = count.Value, count.Count
+		}
+		return section(title, labels, numbers)
+	}
+
+	var labels []string
+	var counts []int
+	for _, length := range report.Lengths {
+		labels = append(labels, strconv.Itoa(length.Length))
+		counts = append(counts, length.Count)
+	}
+	sections := []statsSection{section("Length", labels, counts)}
+
+	labels, counts = nil, nil
+	for _, score := range report.Scores {
+		labels = append(labels, fmt.Sprintf("%d %s", score.Score, score.Label))
+		counts = append(counts, score.Count)
+	}
+	return append(sections,
+		section("Strength score", labels, counts),
+		values("Character classes", report.Compositions),
+		values("Masks", report.Masks),
+		values("Base words", report.BaseWords),
+		values("Prefixes", report.Prefixes),
+		values("Suffixes", report.Suffixes),
+		values("Years", report.Years),
+	)
+}
+
+// writeStatsReport writes the statistics and summary to w in the given format
+func writeStatsReport(w io.Writer, format string, report analyzer.StatsReport, summary analysisSummary) error {
+	switch format {
+	case "text":
+		_, err := io.WriteString(w, formatStatsReport(report, summary))
+		return err
+	case "json":
+		data, err := marshalIndent(statsReport{SchemaVersion: reportSchemaVersion, Stats: report, Summary: summary}, "")
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, data+"\n")
+		return err
+	case "html":
+		return statsTemplate.Execute(w, struct {
+			Summary  analysisSummary
+			Sections []statsSection
+		}{summary, statsSections(report)})
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+// formatStatsReport renders the statistics as text, one bar per row
+func formatStatsReport(report analyzer.StatsReport, summary analysisSummary) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Statistics of %d passwords\n\n", report.Total)
+	for _, section := range statsSections(report) {
+		fmt.Fprintf(&b, "%s:\n", section.Title)
+		if len(section.Rows) == 0 {
+			b.WriteString("  none\n\n")
+			continue
+		}
+		width := 0
+		for _, row := range section.Rows {
+			width = max(width, len([]rune(row.Label)))
+		}
+		for _, row := range section.Rows {
+			bar := strings.Repeat("█", int(row.Width*statsBarWidth/100+0.5))
+			fmt.Fprintf(&b, "  %-*s %8d %5.1f%% %s\n", width, row.Label, row.Count, row.Percent, bar)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "Summary: %d/%d passwords meet all criteria\n", summary.Passed, summary.Total)
+	return b.String()
+}
+
+// statsTemplate is the self-contained html report, without scripts or
+// external resources
+var statsTemplate = template.Must(template.New("stats").Funcs(template.FuncMap{
+	"percent": func(value float64) string { return strconv.FormatFloat(value, 'f', 1, 64) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Password statistics</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem auto; max-width: 60rem; color: #222; }
+h1 { font-size: 1.6rem; }
+h2 { font-size: 1.2rem; margin-top: 2rem; }
+table { border-collapse: collapse; width: 100%; }
+td { padding: 0.2rem 0.5rem; border-bottom: 1px solid #eee; }
+td.label { font-family: ui-monospace, monospace; white-space: nowrap; }
+td.count { text-align: right; font-variant-numeric: tabular-nums; white-space: nowrap; }
+td.bar { width: 50%; }
+.bar div { background: #4a90d9; height: 0.8rem; }
+.empty { color: #888; }
+</style>
+</head>
+<body>
+<h1>Password statistics</h1>
+<p>{{.Summary.Total}} passwords, {{.Summary.Passed}} meet all criteria and {{.Summary.Failed}} fail.</p>
+{{range .Sections}}<h2>{{.Title}}</h2>
+{{if .Rows}}<table>
+{{range .Rows}}<tr><td class="label">{{.Label}}</td><td class="count">{{.Count}}</td><td class="count">{{percent .Percent}}%</td><td class="bar"><div style="width: {{percent .Width}}%"></div></td></tr>
+{{end}}</table>
+{{else}}<p class="empty">None</p>
+{{end}}{{end}}</body>
+</html>
+`))
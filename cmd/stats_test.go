@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
+)
+
+func testStats() (analyzer.StatsReport, analysisSummary) {
+	stats := analyzer.NewStats()
+	for _, password := range []string{"Word<#>", "word<#>", "Summer2024!", "Winter2024!"} {
+		stats.Add(password)
+		stats.AddScore(1)
+	}
+	return stats.Report(analyzer.DefaultStatsTop), analysisSummary{Total: 4, Failed: 4}
+}
+
+func TestWriteStatsReport(t *testing.T) {
+	report, summary := testStats()
+
+	var text bytes.Buffer
+	if err := writeStatsReport(&text, "text", report, summary); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{"Statistics of 4 passwords", "?u?l?l?l?l?l?d?d?d?d?s", "1 weak", "2024!", "Prefixes:\n  none", "Summary: 0/4"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("Text report lacks %q:\n%s", want, text.String())
+		}
+	}
+
+	var data bytes.Buffer
+	if err := writeStatsReport(&data, "json", report, summary); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var decoded statsReport
+	if err := json.Unmarshal(data.Bytes(), &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if decoded.SchemaVersion != reportSchemaVersion || decoded.Stats.Total != 4 || decoded.Summary.Failed != 4 || len(decoded.Stats.Years) != 1 {
+		t.Errorf("Unexpected report: %+v", decoded)
+	}
+}
+
+func TestWriteStatsReportHTML(t *testing.T) {
+	report, summary := testStats()
+
+	var buf bytes.Buffer
+	if err := writeStatsReport(&buf, "html", report, summary); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	html := buf.String()
+	if !strings.HasPrefix(html, "<!DOCTYPE html>") || !strings.Contains(html, "<h2>Base words</h2>") {
+		t.Errorf("Unexpected HTML report:\n%s", html)
+	}
+	// Values of the passwords are escaped, and nothing is loaded from elsewhere
+	if strings.Contains(html, "<#>") || !strings.Contains(html, "&lt;#&gt;") {
+		t.Errorf("HTML report does not escape the passwords:\n%s", html)
+	}
+	for _, external := range []string{"<script", "<link", "src=", "http"} {
+		if strings.Contains(html, external) {
+			t.Errorf("HTML report is not self-contained, it contains %q", external)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
+	"github.com/tmsankaram/password-zen/pkg/generator"
+)
+
+// tuiCmd represents the tui command
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Explore generation options and analysis checks interactively",
+	Long: `Open a terminal UI to explore what the options of 'generate' and the checks of 'analyze' do.
+The generate pane has a length slider and toggles for digits, symbols and ambiguous characters, plus a custom character set. The preview password, its entropy and strength score are updated as the options change, together with the matching 'password-zen generate' command line.
+The analyze pane runs the checks of 'analyze' with its default criteria on every keystroke of a candidate password, which is masked unless revealed.
+Nothing is written to disk, the screen is cleared on exit.
+
+Keys:
+  tab           switch between the generate and analyze panes
+  up, down      select an option
+  left, right   change the length, page up and page down by 10
+  space, enter  flip the selected toggle
+  ctrl+r        generate another preview
+  ctrl+t        reveal or mask the candidate password
+  ctrl+u        clear the candidate password
+  esc, ctrl+c   quit`,
+	Args: cobra.NoArgs,
+	Run:  runTUI,
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+
+	tuiCmd.Flags().IntP("length", "l", 12, "Initial password length")
+	tuiCmd.Flags().BoolP("include-symbols", "s", false, "Initially include special characters")
+	tuiCmd.Flags().BoolP("include-digits", "d", true, "Initially include digits")
+	tuiCmd.Flags().BoolP("exclude-ambiguous", "e", false, "Initially exclude ambiguous characters like il1Lo0O")
+	tuiCmd.Flags().StringP("charset", "c", "", "Initial custom character set")
+}
+
+func runTUI(cmd *cobra.Command, args []string) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		failf(cmd, exitUsage, "Error: tui requires an interactive terminal\n")
+		return
+	}
+
+	opts := generator.Options{}
+	opts.Length, _ = cmd.Flags().GetInt("length")
+	opts.IncludeDigits, _ = cmd.Flags().GetBool("include-digits")
+	opts.IncludeSymbols, _ = cmd.Flags().GetBool("include-symbols")
+	opts.ExcludeAmbiguous, _ = cmd.Flags().GetBool("exclude-ambiguous")
+	opts.Charset, _ = cmd.Flags().GetString("charset")
+	if opts.Length < tuiMinLength || opts.Length > tuiMaxLength {
+		failf(cmd, exitUsage, "Error: Password length must be between %d and %d characters\n", tuiMinLength, tuiMaxLength)
+		return
+	}
+
+	if _, err := tea.NewProgram(newTUIModel(opts), tea.WithAltScreen()).Run(); err != nil {
+		failf(cmd, exitIO, "Error running tui: %v\n", err)
+	}
+}
+
+// Bounds of the length slider, the limits of 'generate'
+const (
+	tuiMinLength = 1
+	tuiMaxLength = 128
+	tuiPageStep  = 10
+)
+
+// tuiSliderWidth is the number of cells of the length slider
+const tuiSliderWidth = 32
+
+// tuiPane is a pane of the terminal UI
+type tuiPane int
+
+const (
+	paneGenerate tuiPane = iota
+	paneAnalyze
+)
+
+// tuiControl is an option of the generate pane, in display order
+type tuiControl int
+
+const (
+	controlLength tuiControl = iota
+	controlDigits
+	controlSymbols
+	controlAmbiguous
+	controlCharset
+	controlCount
+)
+
+// tuiModel is the state of the terminal UI. Every change of the options
+// regenerates the preview, every change of the candidate reanalyzes it.
+type tuiModel struct {
+	pane  tuiPane
+	focus tuiControl
+
+	opts     generator.Options
+	preview  string
+	entropy  float64
+	strength analyzer.Strength
+	err      error
+
+	candidate string
+	reveal    bool
+	criteria  analyzer.Criteria
+	result    analyzer.Result
+}
+
+func newTUIModel(opts generator.Options) *tuiModel {
+	m := &tuiModel{
+		opts:     opts,
+		criteria: flagCriteria(8, 3, false, true, true, true),
+	}
+	m.regenerate()
+	m.analyze()
+	return m
+}
+
+func (m *tuiModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch key.String() {
+	case "ctrl+c", "esc":
+		return m, tea.Quit
+	case "tab", "shift+tab":
+		m.pane = 1 - m.pane
+		return m, nil
+	case "ctrl+r":
+		m.regenerate()
+		return m, nil
+	}
+
+	if m.pane == paneAnalyze {
+		m.updateAnalyze(key)
+	} else {
+		m.updateGenerate(key)
+	}
+	return m, nil
+}
+
+// updateGenerate handles a key of the generate pane
+func (m *tuiModel) updateGenerate(key tea.KeyMsg) {
+	switch key.Type {
+	case tea.KeyUp:
+		m.focus = (m.focus + controlCount - 1) % controlCount
+		return
+	case tea.KeyDown:
+		m.focus = (m.focus + 1) % controlCount
+		return
+	}
+
+	switch m.focus {
+	case controlLength:
+		step := map[tea.KeyType]int{tea.KeyLeft: -1, tea.KeyRight: 1, tea.KeyPgDown: -tuiPageStep, tea.KeyPgUp: tuiPageStep}[key.Type]
+		if length := min(max(m.opts.Length+step, tuiMinLength), tuiMaxLength); step != 0 && length != m.opts.Length {
+			m.opts.Length = length
+			m.regenerate()
+		}
+	case controlCharset:
+		if charset, ok := editText(m.opts.Charset, key); ok {
+			m.opts.Charset = charset
+			m.regenerate()
+		}
+	default:
+		if key.Type != tea.KeySpace && key.Type != tea.KeyEnter && key.Type != tea.KeyLeft && key.Type != tea.KeyRight {
+			return
+		}
+		toggles := map[tuiControl]*bool{
+			controlDigits:    &m.opts.IncludeDigits,
+			controlSymbols:   &m.opts.IncludeSymbols,
+			controlAmbiguous: &m.opts.ExcludeAmbiguous,
+		}
+		*toggles[m.focus] = !*toggles[m.focus]
+		m.regenerate()
+	}
+}
+
+// updateAnalyze handles a key of the analyze pane
+func (m *tuiModel) updateAnalyze(key tea.KeyMsg) {
+	switch key.String() {
+	case "ctrl+t":
+		m.reveal = !m.reveal
+		return
+	case "ctrl+u":
+		m.candidate = ""
+		m.analyze()
+		return
+	}
+	if candidate, ok := editText(m.candidate, key); ok {
+		m.candidate = candidate
+		m.analyze()
+	}
+}
+
+// editText applies a typing key to text and reports whether it changed
+func editText(text string, key tea.KeyMsg) (string, bool) {
+	switch key.Type {
+	case tea.KeyRunes, tea.KeySpace:
+		return text + string(key.Runes), true
+	case tea.KeyBackspace:
+		if text == "" {
+			return text, false
+		}
+		_, size := utf8.DecodeLastRuneInString(text)
+		return text[:len(text)-size], true
+	}
+	return text, false
+}
+
+// regenerate generates a new preview with the defaults of 'generate': at
+// least one character of every class in the character set
+func (m *tuiModel) regenerate() {
+	m.preview, m.entropy, m.strength = "", 0, analyzer.Strength{}
+
+	opts := m.opts
+	minimums := map[string]int{"lower": 1, "upper": 1, "digits": 1, "symbols": 1}
+	if m.err = applyClassMinimums(&opts, minimums, nil, "--min-%s"); m.err != nil {
+		return
+	}
+	if m.preview, m.err = generator.Generate(opts); m.err != nil {
+		return
+	}
+
+	charset := opts.Charset
+	if charset == "" {
+		charset = generator.JoinCharClasses(opts.Classes())
+	}
+	m.entropy = generator.Entropy(opts.Length, charset, opts.Classes())
+	m.strength = analyzer.EstimateStrength(m.preview)
+}
+
+// analyze runs the checks of 'analyze' on the candidate password
+func (m *tuiModel) analyze() {
+	m.result, _ = analyzer.Analyze(m.candidate, m.criteria)
+}
+
+// command returns the 'generate' command line of the current options
+func (m *tuiModel) command() string {
+	args := []string{"password-zen generate", fmt.Sprintf("--length %d", m.opts.Length)}
+	if m.opts.Charset != "" {
+		return strings.Join(append(args, "--charset "+shellQuote(m.opts.Charset)), " ")
+	}
+	if !m.opts.IncludeDigits {
+		args = append(args, "--include-digits=false")
+	}
+	if m.opts.IncludeSymbols {
+		args = append(args, "--include-symbols")
+	}
+	if m.opts.ExcludeAmbiguous {
+		args = append(args, "--exclude-ambiguous")
+	}
+	return strings.Join(args, " ")
+}
+
+func (m *tuiModel) View() string {
+	faint := color.New(color.Faint).SprintFunc()
+
+	var b strings.Builder
+	tabs := []string{" Generate ", " Analyze "}
+	tabs[m.pane] = color.New(color.Bold, color.ReverseVideo).Sprint(tabs[m.pane])
+	fmt.Fprintf(&b, "🔐 Password Zen  %s %s\n\n", tabs[0], tabs[1])
+
+	if m.pane == paneAnalyze {
+		m.viewAnalyze(&b)
+	} else {
+		m.viewGenerate(&b)
+	}
+
+	fmt.Fprintf(&b, "\n%s\n", faint("tab switch pane • ctrl+r regenerate • ctrl+t reveal • esc quit"))
+	return b.String()
+}
+
+// viewGenerate renders the options, the preview and the command line
+func (m *tuiModel) viewGenerate(b *strings.Builder) {
+	faint := color.New(color.Faint).SprintFunc()
+	cursor := func(control tuiControl) string {
+		if control == m.focus {
+			return color.CyanString("▸ ")
+		}
+		return "  "
+	}
+	checkbox := func(on bool) string {
+		if on {
+			return "[x]"
+		}
+		return "[ ]"
+	}
+
+	filled := (m.opts.Length*tuiSliderWidth + tuiMaxLength - 1) / tuiMaxLength
+	fmt.Fprintf(b, "%sLength     %s%s %d\n", cursor(controlLength),
+		color.CyanString(strings.Repeat("█", filled)), faint(strings.Repeat("░", tuiSliderWidth-filled)), m.opts.Length)
+
+	ignored := ""
+	if m.opts.Charset != "" {
+		ignored = faint("  (replaced by the character set)")
+	}
+	fmt.Fprintf(b, "%s%s Include digits%s\n", cursor(controlDigits), checkbox(m.opts.IncludeDigits), ignored)
+	fmt.Fprintf(b, "%s%s Include symbols%s\n", cursor(controlSymbols), checkbox(m.opts.IncludeSymbols), ignored)
+	fmt.Fprintf(b, "%s%s Exclude ambiguous characters%s\n", cursor(controlAmbiguous), checkbox(m.opts.ExcludeAmbiguous), ignored)
+
+	charset := m.opts.Charset
+	if m.focus == controlCharset {
+		charset += "█"
+	} else if charset == "" {
+		charset = faint("built-in classes")
+	}
+	fmt.Fprintf(b, "%sCharset    %s\n\n", cursor(controlCharset), charset)
+
+	if m.err != nil {
+		fmt.Fprintf(b, "%s\n", color.RedString("✗ %v", m.err))
+		return
+	}
+	fmt.Fprintf(b, "Preview    %s\n", color.New(color.Bold).Sprint(m.preview))
+	fmt.Fprintf(b, "Entropy    %.1f bits\n", m.entropy)
+	fmt.Fprintf(b, "Strength   %s\n", scoreColor(m.strength.Score).Sprintf("%d/4 %s", m.strength.Score, m.strength.Label))
+	fmt.Fprintf(b, "\n%s\n", faint("$ "+m.command()))
+}
+
+// viewAnalyze renders the candidate password and its checks
+func (m *tuiModel) viewAnalyze(b *strings.Builder) {
+	candidate := m.candidate
+	if !m.reveal {
+		candidate = strings.Repeat("•", utf8.RuneCountInString(candidate))
+	}
+	fmt.Fprintf(b, "Password   %s█\n\n", candidate)
+
+	if m.candidate == "" {
+		fmt.Fprintf(b, "%s\n", color.New(color.Faint).Sprint("Type a password to run the checks of 'analyze'"))
+		return
+	}
+	for _, check := range m.result.Checks {
+		if check.Passed {
+			fmt.Fprintf(b, "  %s %s\n", color.GreenString("✓"), check.Message)
+		} else {
+			fmt.Fprintf(b, "  %s %s\n", color.RedString("✗"), check.Message)
+		}
+	}
+	if strength := m.result.Strength; len(strength.CrackTimes) > 0 {
+		last := strength.CrackTimes[len(strength.CrackTimes)-1]
+		fmt.Fprintf(b, "    Crack time, %s: %s\n", last.Scenario, last.Display)
+	}
+}
+
+// scoreColor returns the color of a strength score, red for weak to green
+// for strong
+func scoreColor(score int) *color.Color {
+	switch {
+	case score >= 3:
+		return color.New(color.FgGreen, color.Bold)
+	case score == 2:
+		return color.New(color.FgYellow, color.Bold)
+	default:
+		return color.New(color.FgRed, color.Bold)
+	}
+}
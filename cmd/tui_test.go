@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/tmsankaram/password-zen/pkg/generator"
+)
+
+// typeKeys sends the keys of text to the model one rune at a time
+func typeKeys(m *tuiModel, text string) {
+	for _, r := range text {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+func TestTUIGenerate(t *testing.T) {
+	m := newTUIModel(generator.Options{Length: 12, IncludeDigits: true})
+	if utf8.RuneCountInString(m.preview) != 12 || m.entropy == 0 || m.err != nil {
+		t.Fatalf("Initial preview %q, entropy %v, error %v", m.preview, m.entropy, m.err)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	m.Update(tea.KeyMsg{Type: tea.KeyPgUp})
+	if m.opts.Length != 23 || len(m.preview) != 23 {
+		t.Errorf("Length = %d with preview %q, want 23", m.opts.Length, m.preview)
+	}
+	for i := 0; i < 20; i++ {
+		m.Update(tea.KeyMsg{Type: tea.KeyPgUp})
+	}
+	if m.opts.Length != tuiMaxLength {
+		t.Errorf("Length = %d, want the maximum %d", m.opts.Length, tuiMaxLength)
+	}
+
+	// Toggle symbols on and check that the preview and command follow
+	entropy := m.entropy
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	if !m.opts.IncludeSymbols || m.entropy <= entropy {
+		t.Errorf("Symbols = %v with entropy %v, want more than %v", m.opts.IncludeSymbols, m.entropy, entropy)
+	}
+	if want := "password-zen generate --length 128 --include-symbols"; m.command() != want {
+		t.Errorf("command() = %q, want %q", m.command(), want)
+	}
+}
+
+func TestTUICharset(t *testing.T) {
+	m := newTUIModel(generator.Options{Length: 8, IncludeDigits: true})
+	m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if m.focus != controlCharset {
+		t.Fatalf("Focus = %d, want the charset", m.focus)
+	}
+
+	typeKeys(m, "ab'€")
+	if m.opts.Charset != "ab'€" || m.err != nil {
+		t.Fatalf("Charset = %q, error %v", m.opts.Charset, m.err)
+	}
+	for _, char := range m.preview {
+		if !strings.ContainsRune("ab'€", char) {
+			t.Errorf("Preview %q has %q outside the charset", m.preview, char)
+		}
+	}
+	if want := `password-zen generate --length 8 --charset 'ab'\''€'`; m.command() != want {
+		t.Errorf("command() = %q, want %q", m.command(), want)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if m.opts.Charset != "ab'" {
+		t.Errorf("Charset after backspace = %q, want %q", m.opts.Charset, "ab'")
+	}
+
+	// Characters that NFKC normalization changes are rejected
+	typeKeys(m, "ｑ")
+	if m.err == nil || m.preview != "" || !strings.Contains(m.View(), "normalizes") {
+		t.Errorf("Expected an error for a charset changed by NFKC, got preview %q", m.preview)
+	}
+}
+
+func TestTUIAnalyze(t *testing.T) {
+	m := newTUIModel(generator.Options{Length: 12, IncludeDigits: true})
+	m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if m.pane != paneAnalyze {
+		t.Fatalf("Pane = %d, want analyze", m.pane)
+	}
+
+	typeKeys(m, "abc")
+	if m.result.Passed || !strings.Contains(m.View(), "•••") || strings.Contains(m.View(), "abc") {
+		t.Errorf("Expected a masked failing candidate, got %+v", m.result)
+	}
+
+	typeKeys(m, "X9#vQ2mLp7z")
+	if !m.result.Passed {
+		t.Errorf("Expected %q to pass, got %+v", m.candidate, m.result.Checks)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	if !strings.Contains(m.View(), "abcX9#vQ2mLp7z") {
+		t.Errorf("Expected the revealed candidate in the view")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlU})
+	if m.candidate != "" || m.result.Passed {
+		t.Errorf("Expected ctrl+u to clear the candidate, got %q", m.candidate)
+	}
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc}); cmd == nil {
+		t.Errorf("Expected esc to quit")
+	}
+}
@@ -0,0 +1,366 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+
+// Package analyzer checks passwords against a set of criteria and estimates
+// their strength. It is the engine behind 'password-zen analyze'.
+//
+//	result, err := analyzer.Analyze(password, analyzer.Criteria{MinLength: 12, MinScore: 3})
+//	if err == nil && !result.Passed {
+//		for _, check := range result.Checks {
+//			fmt.Println(check.Name, check.Passed, check.Message)
+//		}
+//	}
+package analyzer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ASCII characters of the classes recognised by the checks, from which
+// passwords are generated. The checks recognise the whole Unicode categories.
+const (
+	Lowercase = "abcdefghijklmnopqrstuvwxyz"
+	Uppercase = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	Digits    = "0123456789"
+	Symbols   = "!@#$%^&*()-_=+[]{}|;:,.<>?/"
+)
+
+// Criteria holds the requirements a password is checked against.
+// Zero values disable the corresponding check.
+type Criteria struct {
+	MinLength   int
+	MaxLength   int
+	MinLower    int
+	MinUpper    int
+	MinLetters  int
+	MinDigits   int
+	MinSymbols  int
+	MinClasses  int
+	BannedWords []string
+
+	// ContextTerms are the identifiers of the user, such as the parts of
+	// the user name and email address returned by ContextTerms. Passwords
+	// containing one fail the context check, see FindContextTerms. The
+	// check runs unless ContextTerms is nil.
+	ContextTerms []string
+	MaxRepeated  int
+	MinEntropy   float64
+	MinScore     int
+
+	// Patterns longer than these many characters fail their check, 0
+	// disables the check. See FindKeyboardWalks, FindSequences and
+	// FindRepetitions.
+	MaxKeyboardWalk int
+	MaxSequence     int
+	MaxRepetition   int
+
+	// Breach enables the breach check, passwords seen more than
+	// MaxBreachCount times fail it
+	Breach         BreachChecker
+	MaxBreachCount int
+}
+
+// BreachChecker reports how many times a password appears in a breach corpus.
+// It is implemented by *breach.DB.
+type BreachChecker interface {
+	Count(password string) (int, error)
+}
+
+// Check is the outcome of a single check on a password
+type Check struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+
+	// Findings locates the patterns a pattern check found
+	Findings []Finding `json:"findings,omitempty"`
+}
+
+// Result is the analysis result of a single password
+type Result struct {
+	Passed bool `json:"passed"`
+
+	// Length counts the code points of the normalized password, as NIST SP
+	// 800-63B requires for length rules. Graphemes counts the characters a
+	// user perceives, e.g. 1 for an emoji with a skin tone modifier.
+	Length    int `json:"length"`
+	Graphemes int `json:"graphemes"`
+
+	// Normalized is set when NFKC normalization changed the password
+	Normalized bool `json:"normalized,omitempty"`
+
+	Checks   []Check  `json:"checks"`
+	Strength Strength `json:"strength"`
+
+	// BreachCount is only set when a breach corpus was checked
+	BreachCount *int `json:"breach_count,omitempty"`
+
+	// Entry is only set by AnalyzeStream for CSV files and the exports of
+	// password managers
+	Entry *Entry `json:"entry,omitempty"`
+
+	// Shown is only set by AnalyzeStream with StreamOptions.Show, and is the
+	// form of the password reports show
+	Shown string `json:"password,omitempty"`
+}
+
+// Analyze runs every enabled check on a password and records the results.
+// The password is normalized with Normalize first, except for the breach
+// check, which looks up the password as given and its normalized form, as
+// breach corpora hold passwords as they were leaked. An error is only returned
+// when the breach corpus cannot be read.
+func Analyze(password string, criteria Criteria) (Result, error) {
+	normalized := Normalize(password)
+	result := Result{
+		Passed:     true,
+		Length:     utf8.RuneCountInString(normalized),
+		Graphemes:  uniseg.GraphemeClusterCount(normalized),
+		Normalized: normalized != password,
+	}
+	raw, password := password, normalized
+
+	addCheck := func(name string, passed bool, message string) {
+		result.Checks = append(result.Checks, Check{Name: name, Passed: passed, Message: message})
+		if !passed {
+			result.Passed = false
+		}
+	}
+
+	if result.Length < criteria.MinLength {
+		addCheck("length", false, fmt.Sprintf("Too short (%d < %d characters)", result.Length, criteria.MinLength))
+	} else if criteria.MaxLength > 0 && result.Length > criteria.MaxLength {
+		addCheck("length", false, fmt.Sprintf("Too long (%d > %d characters)", result.Length, criteria.MaxLength))
+	} else {
+		message := fmt.Sprintf("Length: %d characters", result.Length)
+		if result.Graphemes != result.Length {
+			message += fmt.Sprintf(" (%d as displayed)", result.Graphemes)
+		}
+		addCheck("length", true, message)
+	}
+
+	// classCheck reports whether the password has at least min characters of a class
+	classCheck := func(name string, min int, contains func(string) bool, description string) {
+		count := countMatching(password, contains)
+		switch {
+		case min <= 0:
+		case count < min && min == 1:
+			addCheck(name, false, "Missing "+description)
+		case count < min:
+			addCheck(name, false, fmt.Sprintf("Too few %s (%d < %d)", description, count, min))
+		case min == 1:
+			addCheck(name, true, "Contains "+description)
+		default:
+			addCheck(name, true, fmt.Sprintf("Contains %d %s", count, description))
+		}
+	}
+
+	classCheck("symbols", criteria.MinSymbols, ContainsSymbol, "special characters")
+	classCheck("digits", criteria.MinDigits, ContainsDigit, "digits")
+	classCheck("uppercase", criteria.MinUpper, ContainsUppercase, "uppercase letters")
+	classCheck("lowercase", criteria.MinLower, ContainsLowercase, "lowercase letters")
+	classCheck("letters", criteria.MinLetters, ContainsLetter, "letters")
+
+	if criteria.MinClasses > 0 {
+		classes := 0
+		for _, contains := range []func(string) bool{ContainsLowercase, ContainsUppercase, ContainsDigit, ContainsSymbol} {
+			if contains(password) {
+				classes++
+			}
+		}
+		if classes < criteria.MinClasses {
+			addCheck("classes", false, fmt.Sprintf("Too few character classes (%d < %d)", classes, criteria.MinClasses))
+		} else {
+			addCheck("classes", true, fmt.Sprintf("Uses %d character classes", classes))
+		}
+	}
+
+	if len(criteria.BannedWords) > 0 {
+		if word := findBannedWord(password, criteria.BannedWords); word != "" {
+			addCheck("banned_words", false, fmt.Sprintf("Contains banned word %q", word))
+		} else {
+			addCheck("banned_words", true, "No banned words")
+		}
+	}
+
+	if criteria.ContextTerms != nil {
+		check := Check{Name: "context", Passed: true, Message: "No user or company terms"}
+		if check.Findings = FindContextTerms(password, criteria.ContextTerms); len(check.Findings) > 0 {
+			check.Passed = false
+			check.Message = "Contains context term " + describeContextFindings(check.Findings)
+			result.Passed = false
+		}
+		result.Checks = append(result.Checks, check)
+	}
+
+	if criteria.MaxRepeated > 0 {
+		if run := longestRepeatedRun(password); run > criteria.MaxRepeated {
+			addCheck("repeated", false, fmt.Sprintf("Repeats a character %d times in a row (> %d)", run, criteria.MaxRepeated))
+		} else {
+			addCheck("repeated", true, fmt.Sprintf("No character repeated more than %d times in a row", criteria.MaxRepeated))
+		}
+	}
+
+	// patternCheck fails when a pattern is longer than max characters
+	patternCheck := func(name string, max int, find func(string, int) []Finding, description string) {
+		if max <= 0 {
+			return
+		}
+		findings := find(password, max+1)
+		check := Check{Name: name, Passed: len(findings) == 0, Findings: findings}
+		if check.Passed {
+			check.Message = fmt.Sprintf("No %s longer than %d characters", description, max)
+		} else {
+			check.Message = fmt.Sprintf("Contains %s %s", description, describeFindings(findings))
+			result.Passed = false
+		}
+		result.Checks = append(result.Checks, check)
+	}
+
+	patternCheck("keyboard_walk", criteria.MaxKeyboardWalk, FindKeyboardWalks, "keyboard walk")
+	patternCheck("sequence", criteria.MaxSequence, FindSequences, "sequence")
+	patternCheck("repetition", criteria.MaxRepetition, FindRepetitions, "repetition")
+
+	strength := estimateStrength(password)
+	if strength.score < criteria.MinScore {
+		addCheck("strength", false, fmt.Sprintf("Strength score too low: %d/4 %s (< %d)", strength.score, scoreLabels[strength.score], criteria.MinScore))
+	} else {
+		addCheck("strength", true, fmt.Sprintf("Strength score: %d/4 %s", strength.score, scoreLabels[strength.score]))
+	}
+	result.Strength = newStrength(strength)
+
+	if criteria.MinEntropy > 0 {
+		if strength.entropy < criteria.MinEntropy {
+			addCheck("entropy", false, fmt.Sprintf("Entropy too low: %.1f bits (< %g)", strength.entropy, criteria.MinEntropy))
+		} else {
+			addCheck("entropy", true, fmt.Sprintf("Entropy: %.1f bits", strength.entropy))
+		}
+	}
+
+	if criteria.Breach != nil {
+		count, err := criteria.Breach.Count(raw)
+		if err != nil {
+			return result, err
+		}
+		if result.Normalized {
+			normalizedCount, err := criteria.Breach.Count(password)
+			if err != nil {
+				return result, err
+			}
+			count = max(count, normalizedCount)
+		}
+		result.BreachCount = &count
+		if count > criteria.MaxBreachCount {
+			addCheck("breach", false, fmt.Sprintf("Found in breach corpus (seen %d times)", count))
+		} else {
+			addCheck("breach", true, "Not found in breach corpus")
+		}
+	}
+
+	return result, nil
+}
+
+// ReadPasswords reads one password per line, skipping empty lines.
+// Surrounding whitespace is trimmed. Large inputs are better analyzed with
+// AnalyzeStream, which does not hold them in memory.
+func ReadPasswords(r io.Reader) ([]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var passwords []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			passwords = append(passwords, line)
+		}
+	}
+
+	return passwords, nil
+}
+
+// Normalize applies the NFKC normalization NIST SP 800-63B recommends before
+// passwords are checked or hashed, so that e.g. a fullwidth Ａ and a
+// precomposed é match their usual forms
+func Normalize(password string) string {
+	return norm.NFKC.String(password)
+}
+
+// IsSymbol reports whether r is a punctuation or symbol character, such as
+// the characters of Symbols, € or ¿
+func IsSymbol(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// ContainsSymbol reports whether the password contains a punctuation or
+// symbol character
+func ContainsSymbol(password string) bool {
+	return strings.IndexFunc(password, IsSymbol) >= 0
+}
+
+// ContainsDigit reports whether the password contains a decimal digit of any script
+func ContainsDigit(password string) bool {
+	return strings.IndexFunc(password, unicode.IsDigit) >= 0
+}
+
+// ContainsUppercase reports whether the password contains an uppercase letter, such as Ü
+func ContainsUppercase(password string) bool {
+	return strings.IndexFunc(password, unicode.IsUpper) >= 0
+}
+
+// ContainsLowercase reports whether the password contains a lowercase letter, such as ï
+func ContainsLowercase(password string) bool {
+	return strings.IndexFunc(password, unicode.IsLower) >= 0
+}
+
+// ContainsLetter reports whether the password contains a letter of any
+// script, including letters without case
+func ContainsLetter(password string) bool {
+	return strings.IndexFunc(password, unicode.IsLetter) >= 0
+}
+
+// countMatching counts the characters of password for which contains is true
+func countMatching(password string, contains func(string) bool) int {
+	count := 0
+	for _, char := range password {
+		if contains(string(char)) {
+			count++
+		}
+	}
+	return count
+}
+
+// findBannedWord returns the first banned word contained in the password, ignoring case
+func findBannedWord(password string, bannedWords []string) string {
+	lower := strings.ToLower(password)
+	for _, word := range bannedWords {
+		if strings.Contains(lower, strings.ToLower(Normalize(word))) {
+			return word
+		}
+	}
+	return ""
+}
+
+// longestRepeatedRun returns the length of the longest run of one repeated character
+func longestRepeatedRun(password string) int {
+	longest, run := 0, 0
+	var previous rune
+	for i, char := range []rune(password) {
+		if i > 0 && char == previous {
+			run++
+		} else {
+			run = 1
+		}
+		previous = char
+		longest = max(longest, run)
+	}
+	return longest
+}
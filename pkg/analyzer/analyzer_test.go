@@ -0,0 +1,252 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContainsSymbol(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     bool
+	}{
+		{"Password with symbol", "test@123", true},
+		{"Password without symbol", "test123", false},
+		{"Password with multiple symbols", "test!@#$", true},
+		{"Password with currency symbol", "test€123", true},
+		{"Password with accented letters only", "ünïcødé", false},
+		{"Empty password", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContainsSymbol(tt.password); got != tt.want {
+				t.Errorf("ContainsSymbol() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainsDigit(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     bool
+	}{
+		{"Password with digit", "test123", true},
+		{"Password without digit", "testABC", false},
+		{"Password with single digit", "test1", true},
+		{"Password with Arabic-Indic digit", "test٣", true},
+		{"Empty password", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContainsDigit(tt.password); got != tt.want {
+				t.Errorf("ContainsDigit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainsUppercase(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     bool
+	}{
+		{"Password with uppercase", "Test123", true},
+		{"Password without uppercase", "test123", false},
+		{"Password with multiple uppercase", "TEST", true},
+		{"Password with non-ASCII uppercase", "Ünïcødé", true},
+		{"Empty password", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContainsUppercase(tt.password); got != tt.want {
+				t.Errorf("ContainsUppercase() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainsLowercase(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     bool
+	}{
+		{"Password with lowercase", "Test123", true},
+		{"Password without lowercase", "TEST123", false},
+		{"Password with multiple lowercase", "test", true},
+		{"Password with non-ASCII lowercase", "ÜNÏCØDÉ1ß", true},
+		{"Empty password", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContainsLowercase(tt.password); got != tt.want {
+				t.Errorf("ContainsLowercase() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+var testCriteria = Criteria{
+	MinLength: 8,
+	MinDigits: 1,
+	MinUpper:  1,
+	MinLower:  1,
+	MinScore:  3,
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name       string
+		password   string
+		wantPassed bool
+		failed     []string
+	}{
+		{"Strong random password", "kX9#vQ2mLp7z", true, nil},
+		{"Common password with all classes", "Password1", false, []string{"strength"}},
+		{"Short lowercase password", "abc", false, []string{"length", "digits", "uppercase", "strength"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Analyze(tt.password, testCriteria)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if result.Passed != tt.wantPassed {
+				t.Errorf("Passed = %v, want %v", result.Passed, tt.wantPassed)
+			}
+			if result.Length != len(tt.password) {
+				t.Errorf("Length = %d, want %d", result.Length, len(tt.password))
+			}
+
+			var failed []string
+			for _, check := range result.Checks {
+				if !check.Passed {
+					failed = append(failed, check.Name)
+				}
+			}
+			if strings.Join(failed, ",") != strings.Join(tt.failed, ",") {
+				t.Errorf("Failed checks = %v, want %v", failed, tt.failed)
+			}
+		})
+	}
+}
+
+func TestAnalyzeUnicode(t *testing.T) {
+	tests := []struct {
+		name           string
+		password       string
+		wantLength     int
+		wantGraphemes  int
+		wantNormalized bool
+	}{
+		{"Accented letters", "Ünïcødé", 7, 7, false},
+		// NFKC composes e and the combining acute accent into é
+		{"Combining accent", "cafe\u0301", 4, 4, true},
+		// NFKC maps fullwidth letters to ASCII
+		{"Fullwidth letters", "ｐａｓｓ", 4, 4, true},
+		// The thumbs up and its skin tone modifier display as one character
+		{"Emoji with modifier", "ok\U0001F44D\U0001F3FD", 4, 3, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Analyze(tt.password, Criteria{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Length != tt.wantLength || result.Graphemes != tt.wantGraphemes || result.Normalized != tt.wantNormalized {
+				t.Errorf("Length, Graphemes, Normalized = %d, %d, %v, want %d, %d, %v",
+					result.Length, result.Graphemes, result.Normalized, tt.wantLength, tt.wantGraphemes, tt.wantNormalized)
+			}
+		})
+	}
+
+	result, _ := Analyze("Ünïcødé1", Criteria{MinLength: 8, MinUpper: 1, MinLower: 1, MinDigits: 1})
+	for _, check := range result.Checks {
+		if check.Name != "strength" && !check.Passed {
+			t.Errorf("Check %s failed: %s", check.Name, check.Message)
+		}
+	}
+}
+
+// staticBreach is a BreachChecker backed by a map
+type staticBreach map[string]int
+
+func (b staticBreach) Count(password string) (int, error) {
+	return b[password], nil
+}
+
+func TestAnalyzeBreach(t *testing.T) {
+	criteria := Criteria{Breach: staticBreach{"letmein": 3}, MaxBreachCount: 2}
+
+	result, _ := Analyze("letmein", criteria)
+	if result.Passed || result.BreachCount == nil || *result.BreachCount != 3 {
+		t.Errorf("Expected breached password to fail, got %+v", result)
+	}
+
+	result, _ = Analyze("kX9#vQ2mLp7z", criteria)
+	if !result.Passed || result.BreachCount == nil || *result.BreachCount != 0 {
+		t.Errorf("Expected unknown password to pass, got %+v", result)
+	}
+
+	// Both the password as given and its normalized form are looked up
+	criteria.Breach = staticBreach{"ｌｅｔｍｅｉｎ": 5, "letmein": 3}
+	for password, want := range map[string]int{"ｌｅｔｍｅｉｎ": 5, "ｌｅｔmein": 3} {
+		result, _ = Analyze(password, criteria)
+		if result.Passed || result.BreachCount == nil || *result.BreachCount != want {
+			t.Errorf("Expected %q to be seen %d times, got %+v", password, want, result)
+		}
+	}
+}
+
+func TestReadPasswords(t *testing.T) {
+	passwords, err := ReadPasswords(strings.NewReader("first\r\n\n  second  \nthird"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(passwords, ",") != "first,second,third" {
+		t.Errorf("ReadPasswords() = %v", passwords)
+	}
+}
+
+func TestLongestRepeatedRun(t *testing.T) {
+	tests := []struct {
+		password string
+		want     int
+	}{
+		{"", 0},
+		{"abc", 1},
+		{"aabbb", 3},
+		{"xaaaay", 4},
+	}
+
+	for _, tt := range tests {
+		if got := longestRepeatedRun(tt.password); got != tt.want {
+			t.Errorf("longestRepeatedRun(%q) = %d, want %d", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestFindBannedWord(t *testing.T) {
+	banned := []string{"acme", "Password"}
+
+	if got := findBannedWord("MyACME2024!", banned); got != "acme" {
+		t.Errorf("findBannedWord() = %q, want %q", got, "acme")
+	}
+	if got := findBannedWord("xpasswordx", banned); got != "Password" {
+		t.Errorf("findBannedWord() = %q, want %q", got, "Password")
+	}
+	if got := findBannedWord("kX9#vQ2mLp7z", banned); got != "" {
+		t.Errorf("findBannedWord() = %q, want none", got)
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MinContextTermLength is the length of the shortest context term. Shorter
+// parts of a user name or email address would match too many passwords.
+const MinContextTermLength = 3
+
+// ContextTerms splits the identifiers of a user, such as the user name, email
+// address or company name, into the terms checked by the context check: the
+// normalized lowercase value itself and its runs of letters and digits. The top-level
+// domain of an email address is left out, so that example.com yields
+// "example" but not "com". Terms shorter than MinContextTermLength are dropped.
+func ContextTerms(values ...string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	add := func(term string) {
+		if len([]rune(term)) >= MinContextTermLength && !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(Normalize(value)))
+		add(value)
+
+		if at := strings.LastIndex(value, "@"); at >= 0 {
+			if dot := strings.LastIndex(value, "."); dot > at {
+				value = value[:dot]
+			}
+		}
+		for _, part := range strings.FieldsFunc(value, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			add(part)
+		}
+	}
+	return terms
+}
+
+// FindContextTerms finds the terms in the password, ignoring case, reversed
+// and with l33t substitutions such as 4 for a. Terms are expected in lowercase
+// as returned by ContextTerms. The Detail of a finding is the term, its
+// Variant tells how the term was written.
+func FindContextTerms(password string, terms []string) []Finding {
+	runes := []rune(password)
+	var findings []Finding
+	for _, term := range terms {
+		forward := []rune(term)
+		reversed := make([]rune, len(forward))
+		for i, r := range forward {
+			reversed[len(forward)-1-i] = r
+		}
+
+		for start := 0; start+len(forward) <= len(runes); start++ {
+			for _, form := range []struct {
+				runes    []rune
+				reversed bool
+			}{{forward, false}, {reversed, true}} {
+				l33t, ok := matchContextTerm(runes[start:start+len(forward)], form.runes)
+				if !ok {
+					continue
+				}
+				var variants []string
+				if form.reversed {
+					variants = append(variants, "reversed")
+				}
+				if l33t {
+					variants = append(variants, "l33t")
+				}
+				findings = append(findings, Finding{
+					Pattern: "context",
+					Token:   string(runes[start : start+len(forward)]),
+					Start:   start,
+					End:     start + len(forward),
+					Detail:  term,
+					Variant: strings.Join(variants, " "),
+				})
+				break
+			}
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Start < findings[j].Start })
+	return longestFindings(findings, 0)
+}
+
+// matchContextTerm reports whether chars spell term ignoring case, and
+// whether l33t substitutions were needed
+func matchContextTerm(chars, term []rune) (l33t, ok bool) {
+	for i, char := range chars {
+		if unicode.ToLower(char) == term[i] {
+			continue
+		}
+		if !containsRune(l33tTable[term[i]], char) {
+			return false, false
+		}
+		l33t = true
+	}
+	return l33t, true
+}
+
+// describeContextFindings lists context findings for a check message
+func describeContextFindings(findings []Finding) string {
+	descriptions := make([]string, len(findings))
+	for i, finding := range findings {
+		description := fmt.Sprintf("%q", finding.Detail)
+		if strings.ToLower(finding.Token) != finding.Detail || finding.Variant != "" {
+			description += fmt.Sprintf(" as %q", finding.Token)
+		}
+		if finding.Variant != "" {
+			description += " (" + finding.Variant + ")"
+		}
+		descriptions[i] = description + fmt.Sprintf(" at %d-%d", finding.Start+1, finding.End)
+	}
+	return strings.Join(descriptions, ", ")
+}
@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContextTerms(t *testing.T) {
+	got := ContextTerms("Alice", "alice.smith@example.com", " Acme Corp ", "al")
+	want := []string{"alice", "alice.smith@example.com", "smith", "example", "acme corp", "acme", "corp"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("ContextTerms() = %v, want %v", got, want)
+	}
+}
+
+func TestFindContextTerms(t *testing.T) {
+	terms := []string{"alice", "acme"}
+	tests := []struct {
+		name     string
+		password string
+		want     string
+	}{
+		{"Case-folded", "xALICE!", "ALICE 1-6 alice"},
+		{"Reversed", "ecila2024", "ecila 0-5 alice reversed"},
+		{"L33t", "4l1c3!", "4l1c3 0-5 alice l33t"},
+		{"Reversed l33t", "3m(@", "3m(@ 0-4 acme reversed l33t"},
+		{"Several terms", "Acme-Alice", "Acme 0-4 acme; Alice 5-10 alice"},
+		{"No term", "kX9#vQ2mLp7z", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var descriptions []string
+			for _, finding := range FindContextTerms(tt.password, terms) {
+				descriptions = append(descriptions, strings.TrimSpace(describeFindingsWithDetail([]Finding{finding})+" "+finding.Variant))
+			}
+			if got := strings.Join(descriptions, "; "); got != tt.want {
+				t.Errorf("Findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeContext(t *testing.T) {
+	result, _ := Analyze("Xec1l4!2024", Criteria{ContextTerms: []string{"alice"}})
+	if result.Passed || len(result.Checks) < 2 || result.Checks[1].Name != "context" {
+		t.Fatalf("Expected the context check to fail, got %+v", result.Checks)
+	}
+	if want := `Contains context term "alice" as "ec1l4" (reversed l33t) at 2-6`; result.Checks[1].Message != want {
+		t.Errorf("Message = %q, want %q", result.Checks[1].Message, want)
+	}
+
+	result, _ = Analyze("kX9#vQ2mLp7z", Criteria{ContextTerms: []string{}})
+	if !result.Passed || len(result.Checks) < 2 || result.Checks[1].Name != "context" {
+		t.Errorf("Expected an empty term list to run and pass the context check, got %+v", result.Checks)
+	}
+}
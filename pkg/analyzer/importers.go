@@ -0,0 +1,477 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package analyzer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Input formats of StreamOptions.Format
+const (
+	FormatLines         = "lines"
+	FormatCSV           = "csv"
+	FormatBitwardenJSON = "bitwarden-json"
+	FormatBitwardenCSV  = "bitwarden-csv"
+	FormatKeePassXML    = "keepass-xml"
+	FormatKeePassCSV    = "keepass-csv"
+	Format1PasswordCSV  = "1password-csv"
+	FormatLastPassCSV   = "lastpass-csv"
+	FormatChromeCSV     = "chrome-csv"
+	FormatFirefoxCSV    = "firefox-csv"
+)
+
+// InputFormats lists the input formats of AnalyzeStream
+var InputFormats = []string{
+	FormatLines, FormatCSV,
+	FormatBitwardenJSON, FormatBitwardenCSV,
+	FormatKeePassXML, FormatKeePassCSV,
+	Format1PasswordCSV, FormatLastPassCSV,
+	FormatChromeCSV, FormatFirefoxCSV,
+}
+
+// Entry identifies where a password was read from: its line and, for the
+// exports of password managers, the title, URL and username of its entry.
+// Only these fields are read from an entry, notes, one-time password secrets
+// and custom fields are skipped.
+type Entry struct {
+	Line     int    `json:"line"`
+	Title    string `json:"title,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// String describes the entry by its title, username and URL, e.g.
+// "GitHub (alice, https://github.com)", or is empty when they are
+func (e Entry) String() string {
+	var details []string
+	for _, detail := range []string{e.Username, e.URL} {
+		if detail != "" {
+			details = append(details, detail)
+		}
+	}
+	switch {
+	case e.Title != "" && len(details) > 0:
+		return fmt.Sprintf("%s (%s)", e.Title, strings.Join(details, ", "))
+	case e.Title != "":
+		return e.Title
+	default:
+		return strings.Join(details, ", ")
+	}
+}
+
+// streamRecord is a password read by AnalyzeStream with its entry and the
+// context terms of the entry
+type streamRecord struct {
+	entry    Entry
+	password string
+	context  []string
+}
+
+// csvLayout maps the columns of a CSV export to the fields of an entry. Each
+// field lists the accepted column names, compared ignoring case. A layout is
+// detected when the header has every column of markers.
+type csvLayout struct {
+	format   string
+	markers  []string
+	password []string
+	title    []string
+	url      []string
+	username []string
+}
+
+// csvLayouts are the CSV exports of password managers, in detection order
+var csvLayouts = []csvLayout{
+	{
+		format:   FormatBitwardenCSV,
+		markers:  []string{"login_password", "login_uri"},
+		password: []string{"login_password"},
+		title:    []string{"name"},
+		url:      []string{"login_uri"},
+		username: []string{"login_username"},
+	},
+	{
+		format:   FormatFirefoxCSV,
+		markers:  []string{"formactionorigin", "httprealm"},
+		password: []string{"password"},
+		url:      []string{"url"},
+		username: []string{"username"},
+	},
+	{
+		format:   FormatLastPassCSV,
+		markers:  []string{"grouping", "extra"},
+		password: []string{"password"},
+		title:    []string{"name"},
+		url:      []string{"url"},
+		username: []string{"username"},
+	},
+	{
+		format:   Format1PasswordCSV,
+		markers:  []string{"title", "otpauth"},
+		password: []string{"password"},
+		title:    []string{"title"},
+		url:      []string{"url", "website", "urls"},
+		username: []string{"username"},
+	},
+	// KeePassXC, then KeePass 2
+	{
+		format:   FormatKeePassCSV,
+		markers:  []string{"group", "title"},
+		password: []string{"password"},
+		title:    []string{"title", "account"},
+		url:      []string{"url", "web site"},
+		username: []string{"username", "login name"},
+	},
+	{
+		format:   FormatKeePassCSV,
+		markers:  []string{"account", "login name"},
+		password: []string{"password"},
+		title:    []string{"title", "account"},
+		url:      []string{"url", "web site"},
+		username: []string{"username", "login name"},
+	},
+	{
+		format:   FormatChromeCSV,
+		markers:  []string{"name", "url", "username"},
+		password: []string{"password"},
+		title:    []string{"name"},
+		url:      []string{"url"},
+		username: []string{"username"},
+	},
+}
+
+// genericLayout reads CSV files of no known export
+var genericLayout = csvLayout{
+	format:   FormatCSV,
+	password: []string{"password"},
+	title:    []string{"title", "name", "account"},
+	url:      []string{"url", "website", "web site", "uri", "login_uri"},
+	username: []string{"username", "user name", "login name", "login_username", "login", "user", "email"},
+}
+
+// csvReader returns the passwords of the rows of r one by one, and io.EOF at
+// the end. The first row names the columns. For FormatCSV, the layout of a
+// known export is detected from the header, otherwise passwordColumn names
+// the password column and every other column adds context terms.
+func csvReader(r io.Reader, format, passwordColumn string) func() (streamRecord, error) {
+	reader := csv.NewReader(r)
+	var layout *csvLayout
+	var columns struct{ password, title, url, username int }
+	generic := false
+
+	return func() (streamRecord, error) {
+		if layout == nil {
+			header, err := reader.Read()
+			if err == io.EOF {
+				return streamRecord{}, err
+			} else if err != nil {
+				return streamRecord{}, fmt.Errorf("reading CSV header: %v", err)
+			}
+			names := make(map[string]int)
+			for i, name := range header {
+				names[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+			}
+
+			if layout, err = detectCSVLayout(names, format, passwordColumn); err != nil {
+				return streamRecord{}, err
+			}
+			generic = layout.format == FormatCSV
+			column := func(candidates []string) int {
+				for _, candidate := range candidates {
+					if i, ok := names[candidate]; ok {
+						return i
+					}
+				}
+				return -1
+			}
+			columns.password, columns.title = column(layout.password), column(layout.title)
+			columns.url, columns.username = column(layout.url), column(layout.username)
+			if columns.password < 0 {
+				return streamRecord{}, fmt.Errorf("CSV header has no %s column", layout.password[0])
+			}
+		}
+
+		row, err := reader.Read()
+		if err != nil {
+			return streamRecord{}, err
+		}
+		field := func(i int) string {
+			if i < 0 {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+
+		line, _ := reader.FieldPos(columns.password)
+		record := streamRecord{
+			entry: Entry{
+				Line:     line,
+				Title:    field(columns.title),
+				URL:      sanitizeURL(field(columns.url)),
+				Username: field(columns.username),
+			},
+			password: row[columns.password],
+		}
+		if generic {
+			for i, value := range row {
+				if i != columns.password {
+					record.context = append(record.context, value)
+				}
+			}
+		} else {
+			record.context = []string{record.entry.Title, record.entry.Username}
+		}
+		return record, nil
+	}
+}
+
+// detectCSVLayout returns the layout of the header with the given column
+// names. An explicit format must match its layout.
+func detectCSVLayout(names map[string]int, format, passwordColumn string) (*csvLayout, error) {
+	matches := func(layout csvLayout) bool {
+		for _, marker := range layout.markers {
+			if _, ok := names[marker]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	if format != FormatCSV {
+		for i := range csvLayouts {
+			if csvLayouts[i].format == format && matches(csvLayouts[i]) {
+				return &csvLayouts[i], nil
+			}
+		}
+		return nil, fmt.Errorf("CSV header is not a %s export", format)
+	}
+
+	if passwordColumn == "" {
+		for i := range csvLayouts {
+			if matches(csvLayouts[i]) {
+				return &csvLayouts[i], nil
+			}
+		}
+	}
+	layout := genericLayout
+	if passwordColumn != "" {
+		layout.password = []string{strings.ToLower(strings.TrimSpace(passwordColumn))}
+	}
+	return &layout, nil
+}
+
+// bitwardenItem is an item of a Bitwarden JSON export. Only logins, type 1,
+// have passwords.
+type bitwardenItem struct {
+	Type  int    `json:"type"`
+	Name  string `json:"name"`
+	Login *struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		URIs     []struct {
+			URI string `json:"uri"`
+		} `json:"uris"`
+	} `json:"login"`
+}
+
+// bitwardenReader returns the logins of a Bitwarden JSON export one by one,
+// and io.EOF at the end. The items are decoded one at a time, so exports of
+// any size are read in constant memory.
+func bitwardenReader(r io.Reader) func() (streamRecord, error) {
+	lines := &lineCounter{r: r}
+	decoder := json.NewDecoder(lines)
+	inItems, done := false, false
+
+	// next moves the decoder to the start of the next item
+	next := func() (bool, error) {
+		if !inItems {
+			if token, err := decoder.Token(); err != nil {
+				return false, fmt.Errorf("reading Bitwarden export: %v", err)
+			} else if token != json.Delim('{') {
+				return false, errors.New("Bitwarden export is not a JSON object")
+			}
+			for {
+				if !decoder.More() {
+					return false, errors.New("Bitwarden export has no items")
+				}
+				key, err := decoder.Token()
+				if err != nil {
+					return false, fmt.Errorf("reading Bitwarden export: %v", err)
+				}
+				switch key {
+				case "encrypted":
+					var encrypted bool
+					if err := decoder.Decode(&encrypted); err != nil {
+						return false, fmt.Errorf("reading Bitwarden export: %v", err)
+					}
+					if encrypted {
+						return false, errors.New("encrypted Bitwarden exports are not supported, export as unencrypted JSON")
+					}
+					continue
+				case "items":
+					if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+						return false, errors.New("Bitwarden export items are not a list")
+					}
+					inItems = true
+				default:
+					var skipped json.RawMessage
+					if err := decoder.Decode(&skipped); err != nil {
+						return false, fmt.Errorf("reading Bitwarden export: %v", err)
+					}
+					continue
+				}
+				break
+			}
+		}
+		return decoder.More(), nil
+	}
+
+	return func() (streamRecord, error) {
+		for !done {
+			more, err := next()
+			if err != nil {
+				return streamRecord{}, err
+			}
+			if !more {
+				done = true
+				break
+			}
+
+			from := decoder.InputOffset()
+			var item bitwardenItem
+			if err := decoder.Decode(&item); err != nil {
+				return streamRecord{}, fmt.Errorf("reading Bitwarden item: %v", err)
+			}
+			line := lines.valueLine(from, decoder.InputOffset())
+			if item.Type != 1 || item.Login == nil {
+				continue
+			}
+
+			entry := Entry{Line: line, Title: item.Name, Username: item.Login.Username}
+			if len(item.Login.URIs) > 0 {
+				entry.URL = sanitizeURL(item.Login.URIs[0].URI)
+			}
+			return streamRecord{entry: entry, password: item.Login.Password, context: []string{entry.Title, entry.Username}}, nil
+		}
+		return streamRecord{}, io.EOF
+	}
+}
+
+// keepassEntry is an entry of a KeePass 2 XML export. Its History holds the
+// entry's earlier versions, which are skipped.
+type keepassEntry struct {
+	Strings []struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	} `xml:"String"`
+}
+
+// keepassReader returns the entries of a KeePass 2 XML export one by one, and
+// io.EOF at the end
+func keepassReader(r io.Reader) func() (streamRecord, error) {
+	decoder := xml.NewDecoder(r)
+	root := false
+	return func() (streamRecord, error) {
+		for {
+			token, err := decoder.Token()
+			if err == io.EOF && !root {
+				return streamRecord{}, errors.New("KeePass export has no KeePassFile element")
+			} else if err != nil {
+				if err != io.EOF {
+					err = fmt.Errorf("reading KeePass export: %v", err)
+				}
+				return streamRecord{}, err
+			}
+
+			start, ok := token.(xml.StartElement)
+			if !ok {
+				continue
+			}
+			switch start.Name.Local {
+			case "KeePassFile":
+				root = true
+			case "History":
+				if err := decoder.Skip(); err != nil {
+					return streamRecord{}, fmt.Errorf("reading KeePass export: %v", err)
+				}
+			case "Entry":
+				line, _ := decoder.InputPos()
+				var entry keepassEntry
+				if err := decoder.DecodeElement(&entry, &start); err != nil {
+					return streamRecord{}, fmt.Errorf("reading KeePass entry on line %d: %v", line, err)
+				}
+
+				record := streamRecord{entry: Entry{Line: line}}
+				for _, field := range entry.Strings {
+					switch field.Key {
+					case "Title":
+						record.entry.Title = strings.TrimSpace(field.Value)
+					case "UserName":
+						record.entry.Username = strings.TrimSpace(field.Value)
+					case "URL":
+						record.entry.URL = sanitizeURL(strings.TrimSpace(field.Value))
+					case "Password":
+						record.password = field.Value
+					}
+				}
+				record.context = []string{record.entry.Title, record.entry.Username}
+				return record, nil
+			}
+		}
+	}
+}
+
+// sanitizeURL drops the credentials, query and fragment of an entry's URL,
+// which may carry secrets such as tokens, so that reports never contain them
+func sanitizeURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" && u.Opaque == "" {
+		// A host without a scheme, such as example.com/login
+		if u, err = url.Parse("//" + raw); err != nil || u.Host == "" {
+			return ""
+		}
+		u.User, u.RawQuery, u.Fragment = nil, "", ""
+		return strings.TrimPrefix(u.String(), "//")
+	}
+	u.User, u.RawQuery, u.ForceQuery, u.Fragment, u.RawFragment = nil, "", false, "", ""
+	return u.String()
+}
+
+// lineCounter keeps the bytes read through it since the end of the last value
+// decoded from them, to find the line a value starts on
+type lineCounter struct {
+	r     io.Reader
+	buf   []byte
+	start int64
+	line  int
+}
+
+func (l *lineCounter) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.buf = append(l.buf, p[:n]...)
+	return n, err
+}
+
+// valueLine returns the 1-based line of the value decoded between the offsets
+// from and to, skipping the comma and whitespace before it, and forgets the
+// bytes before to. Offsets must not decrease between calls.
+func (l *lineCounter) valueLine(from, to int64) int {
+	before, value := l.buf[:from-l.start], l.buf[from-l.start:to-l.start]
+	skipped := len(value) - len(bytes.TrimLeft(value, ", \t\r\n"))
+	line := l.line + bytes.Count(before, []byte("\n")) + bytes.Count(value[:skipped], []byte("\n")) + 1
+
+	l.line += bytes.Count(l.buf[:to-l.start], []byte("\n"))
+	l.buf, l.start = l.buf[to-l.start:], to
+	return line
+}
@@ -0,0 +1,142 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package analyzer
+
+import (
+	"strings"
+	"sync"
+)
+
+// Keyboard layouts used to detect keyboard walks. Each key is written as its
+// unshifted character followed by its shifted character; the rows are offset the
+// same way the keys are on a physical keyboard.
+const (
+	qwertyLayout = `
+` + "`~" + ` 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) -_ =+
+    qQ wW eE rR tT yY uU iI oO pP [{ ]} \|
+     aA sS dD fF gG hH jJ kK lL ;: '"
+      zZ xX cC vV bB nN mM ,< .> /?
+`
+
+	dvorakLayout = `
+` + "`~" + ` 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) [{ ]}
+    '" ,< .> pP yY fF gG cC rR lL /? =+ \|
+     aA oO eE uU iI dD hH tT nN sS -_
+      ;: qQ jJ kK xX bB mM wW vV zZ
+`
+
+	// The French AZERTY layout types digits with shift. Its ISO key left of w
+	// moves the bottom row one key to the left.
+	azertyLayout = `
+²³ &1 é2 "3 '4 (5 -6 è7 _8 ç9 à0 )° =+
+    aA zZ eE rR tT yY uU iI oO pP ^¨ $£
+     qQ sS dD fF gG hH jJ kK lL mM ù% *µ
+   <> wW xX cC vV bB nN ,? ;. :/ !§
+`
+
+	keypadLayout = `
+  / * -
+7 8 9 +
+4 5 6
+1 2 3
+  0 .
+`
+)
+
+// adjacencyGraph maps every character to the keys next to it. Neighbours are
+// stored per direction, with "" where there is no key, so that changes in
+// direction can be counted as turns.
+type adjacencyGraph map[rune][]string
+
+// keyboardGraph is an adjacency graph together with the statistics needed to
+// estimate how many keyboard walks of a given shape exist
+type keyboardGraph struct {
+	name          string
+	graph         adjacencyGraph
+	startingCount float64
+	averageDegree float64
+	shifted       map[rune]bool
+}
+
+// keyboardGraphs returns the keyboard layouts searched for keyboard walks
+var keyboardGraphs = sync.OnceValue(func() []keyboardGraph {
+	return []keyboardGraph{
+		newKeyboardGraph("qwerty", qwertyLayout, true),
+		newKeyboardGraph("azerty", azertyLayout, true),
+		newKeyboardGraph("dvorak", dvorakLayout, true),
+		newKeyboardGraph("keypad", keypadLayout, false),
+	}
+})
+
+func newKeyboardGraph(name, layout string, slanted bool) keyboardGraph {
+	graph := buildAdjacencyGraph(layout, slanted)
+
+	// The second character of a two character key needs shift
+	shifted := make(map[rune]bool)
+	for _, key := range strings.Fields(layout) {
+		if runes := []rune(key); len(runes) == 2 {
+			shifted[runes[1]] = true
+		}
+	}
+
+	degrees := 0
+	for _, neighbours := range graph {
+		for _, neighbour := range neighbours {
+			if neighbour != "" {
+				degrees++
+			}
+		}
+	}
+
+	return keyboardGraph{
+		name:          name,
+		graph:         graph,
+		startingCount: float64(len(graph)),
+		averageDegree: float64(degrees) / float64(len(graph)),
+		shifted:       shifted,
+	}
+}
+
+// buildAdjacencyGraph builds an adjacency graph from a layout drawing. Slanted
+// layouts (regular keyboards) have six neighbours per key, aligned layouts
+// (keypads) have eight.
+func buildAdjacencyGraph(layout string, slanted bool) adjacencyGraph {
+	type coord struct{ x, y int }
+
+	positions := make(map[coord]string)
+	tokens := strings.Fields(layout)
+	unit := len([]rune(tokens[0])) + 1
+
+	for y, line := range strings.Split(layout, "\n") {
+		slant := 0
+		if slanted {
+			slant = y - 1
+		}
+		offset := 0
+		for _, token := range strings.Fields(line) {
+			index := strings.Index(line[offset:], token) + offset
+			offset = index + len(token)
+			x := (len([]rune(line[:index])) - slant) / unit
+			positions[coord{x, y}] = token
+		}
+	}
+
+	neighbours := func(x, y int) []coord {
+		if slanted {
+			return []coord{{x - 1, y}, {x, y - 1}, {x + 1, y - 1}, {x + 1, y}, {x, y + 1}, {x - 1, y + 1}}
+		}
+		return []coord{{x - 1, y}, {x - 1, y - 1}, {x, y - 1}, {x + 1, y - 1}, {x + 1, y}, {x + 1, y + 1}, {x, y + 1}, {x - 1, y + 1}}
+	}
+
+	graph := make(adjacencyGraph)
+	for position, key := range positions {
+		for _, char := range key {
+			for _, neighbour := range neighbours(position.x, position.y) {
+				graph[char] = append(graph[char], positions[neighbour])
+			}
+		}
+	}
+
+	return graph
+}
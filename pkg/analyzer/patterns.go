@@ -0,0 +1,173 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package analyzer
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Finding is a pattern found in a password by a pattern check. Start and End
+// are rune offsets into the password, End is exclusive, as for Pattern.
+type Finding struct {
+	Pattern string `json:"pattern"`
+	Token   string `json:"token"`
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+
+	// Detail is the keyboard layout of a walk, the alphabet of a sequence,
+	// the repeated unit of a repetition or the term of a context finding
+	Detail string `json:"detail,omitempty"`
+
+	// Variant tells how a context term was written: "reversed", "l33t" or
+	// both, empty when only its case differs
+	Variant string `json:"variant,omitempty"`
+}
+
+// Length returns the number of characters of the finding
+func (f Finding) Length() int {
+	return f.End - f.Start
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%q at %d-%d", f.Token, f.Start+1, f.End)
+}
+
+// FindKeyboardWalks finds walks of at least minLength adjacent keys, shifted
+// or not, on the qwerty, azerty, dvorak and keypad layouts. Walks that overlap
+// or touch, such as !QAZ and 2wsx in !QAZ2wsx, are one walk that jumps or
+// changes layout, and Detail lists all layouts of it.
+func FindKeyboardWalks(password string, minLength int) []Finding {
+	runes := []rune(password)
+	var findings []Finding
+	for _, match := range spatialMatch(runes) {
+		findings = append(findings, Finding{Pattern: "keyboard_walk", Token: match.token, Start: match.i, End: match.j + 1, Detail: match.graph})
+	}
+	return longestFindings(mergeFindings(runes, longestFindings(findings, 0)), minLength)
+}
+
+// FindSequences finds ascending or descending runs of letters or digits with
+// a constant step, such as abcd, 9876 or 1357, of at least minLength characters
+func FindSequences(password string, minLength int) []Finding {
+	var findings []Finding
+	for _, match := range sequenceMatch([]rune(password)) {
+		if match.sequenceName == "unicode" {
+			continue
+		}
+		direction := "descending"
+		if match.ascending {
+			direction = "ascending"
+		}
+		findings = append(findings, Finding{Pattern: "sequence", Token: match.token, Start: match.i, End: match.j + 1, Detail: direction + " " + match.sequenceName})
+	}
+	return longestFindings(findings, minLength)
+}
+
+// FindRepetitions finds repeated substrings such as aaaa or abcabc of at
+// least minLength characters in total, of units of up to 50 characters
+func FindRepetitions(password string, minLength int) []Finding {
+	var findings []Finding
+	runes := []rune(password)
+	for i := 0; i < len(runes); {
+		unit, count := longestRepetition(runes[i:])
+		if count < 2 {
+			i++
+			continue
+		}
+		end := i + unit*count
+		findings = append(findings, Finding{Pattern: "repetition", Token: string(runes[i:end]), Start: i, End: end, Detail: string(runes[i : i+unit])})
+		i = end
+	}
+	return longestFindings(findings, minLength)
+}
+
+// maxRepetitionUnit is the longest unit of repetitions FindRepetitions
+// finds, half of what the strength estimator matches, which keeps it linear
+// in the length of the password
+const maxRepetitionUnit = maxMatchedLength / 2
+
+// longestRepetition returns the unit and count of the longest repetition at
+// the start of runes. Of repetitions of the same length, the one with the
+// shortest unit is returned.
+func longestRepetition(runes []rune) (unit, count int) {
+	for size := 1; size <= maxRepetitionUnit && 2*size <= len(runes); size++ {
+		n := 1
+		for (n+1)*size <= len(runes) && slices.Equal(runes[:size], runes[n*size:(n+1)*size]) {
+			n++
+		}
+		if n >= 2 && size*n > unit*count {
+			unit, count = size, n
+		}
+	}
+	return unit, count
+}
+
+// longestFindings keeps the findings of at least minLength characters and
+// drops those contained in another one, e.g. a walk found on two layouts
+func longestFindings(findings []Finding, minLength int) []Finding {
+	// In order of start, longest first, a finding is contained in another
+	// when an earlier one ends at or after it. Of equal findings the first
+	// is kept.
+	order := make([]int, len(findings))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		first, second := findings[order[a]], findings[order[b]]
+		if first.Start != second.Start {
+			return first.Start < second.Start
+		}
+		return first.Length() > second.Length()
+	})
+
+	keep := make([]bool, len(findings))
+	end := -1
+	for _, i := range order {
+		if findings[i].End > end {
+			keep[i] = findings[i].Length() >= minLength
+			end = findings[i].End
+		}
+	}
+
+	var kept []Finding
+	for i, finding := range findings {
+		if keep[i] {
+			kept = append(kept, finding)
+		}
+	}
+	return kept
+}
+
+// mergeFindings joins the findings that overlap or touch into one finding,
+// whose Detail lists the distinct details of the joined ones
+func mergeFindings(runes []rune, findings []Finding) []Finding {
+	sorted := slices.Clone(findings)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	var merged []Finding
+	for _, finding := range sorted {
+		if len(merged) == 0 || finding.Start > merged[len(merged)-1].End {
+			merged = append(merged, finding)
+			continue
+		}
+		last := &merged[len(merged)-1]
+		last.End = max(last.End, finding.End)
+		last.Token = string(runes[last.Start:last.End])
+		if !slices.Contains(strings.Split(last.Detail, ", "), finding.Detail) {
+			last.Detail += ", " + finding.Detail
+		}
+	}
+	return merged
+}
+
+// describeFindings lists findings for a check message
+func describeFindings(findings []Finding) string {
+	descriptions := make([]string, len(findings))
+	for i, finding := range findings {
+		descriptions[i] = finding.String()
+	}
+	return strings.Join(descriptions, ", ")
+}
@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func describeFindingsWithDetail(findings []Finding) string {
+	var descriptions []string
+	for _, finding := range findings {
+		descriptions = append(descriptions, fmt.Sprintf("%s %d-%d %s", finding.Token, finding.Start, finding.End, finding.Detail))
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+func TestFindPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		find     func(string, int) []Finding
+		password string
+		want     string
+	}{
+		{"qwerty walk", FindKeyboardWalks, "xQwErty!", "QwErty 1-7 qwerty"},
+		{"shifted qwerty walk", FindKeyboardWalks, "9!@#$", "!@#$ 1-5 qwerty"},
+		{"azerty walk", FindKeyboardWalks, "azerty1", "azerty 0-6 azerty"},
+		{"dvorak walk", FindKeyboardWalks, "aoeuid", "aoeuid 0-6 dvorak"},
+		{"walk across layouts", FindKeyboardWalks, "!QAZ2wsx", "!QAZ2wsx 0-8 qwerty, azerty"},
+		{"touching walks", FindKeyboardWalks, "qwerasdf9", "qwerasdf 0-8 qwerty"},
+		{"separate walks", FindKeyboardWalks, "qwer9zxcv", "qwer 0-4 qwerty; zxcv 5-9 qwerty"},
+		{"no walk", FindKeyboardWalks, "qpXm", ""},
+		{"ascending letters", FindSequences, "Xabcd9", "abcd 1-5 ascending lower"},
+		{"descending digits", FindSequences, "pw9876", "9876 2-6 descending digits"},
+		{"stepped sequence", FindSequences, "1357", "1357 0-4 ascending digits"},
+		{"no sequence", FindSequences, "kX9#vQ2m", ""},
+		{"repeated character", FindRepetitions, "xaaaay", "aaaa 1-5 a"},
+		{"repeated substring", FindRepetitions, "abcabcabc!", "abcabcabc 0-9 abc"},
+		{"multibyte offsets", FindRepetitions, "ééxyxy", "éé 0-2 é; xyxy 2-6 xy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeFindingsWithDetail(tt.find(tt.password, 2)); got != tt.want {
+				t.Errorf("Findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindPatternsMinLength(t *testing.T) {
+	if findings := FindSequences("abcd", 5); len(findings) != 0 {
+		t.Errorf("Expected no sequence of 5 characters, got %v", findings)
+	}
+	if findings := FindRepetitions("aaab", 4); len(findings) != 0 {
+		t.Errorf("Expected no repetition of 4 characters, got %v", findings)
+	}
+}
+
+func TestFindRepetitionsLong(t *testing.T) {
+	// Characters that never repeat around a long repetition, as in a crafted
+	// line of an audit dump
+	var password strings.Builder
+	for i := 0; i < 20000; i++ {
+		password.WriteRune(rune(0x4e00 + i))
+	}
+	password.WriteString(strings.Repeat("xy", 10000))
+
+	start := time.Now()
+	findings := FindRepetitions(password.String(), 4)
+	if len(findings) != 1 || findings[0].Start != 20000 || findings[0].Detail != "xy" {
+		t.Errorf("Unexpected findings %v", findings)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("FindRepetitions took %v", elapsed)
+	}
+}
+
+func TestAnalyzePatterns(t *testing.T) {
+	criteria := Criteria{MaxKeyboardWalk: 3, MaxSequence: 3, MaxRepetition: 3}
+
+	result, _ := Analyze("Xqwer!1234!zzzz", criteria)
+	want := map[string]string{
+		"keyboard_walk": `Contains keyboard walk "qwer" at 2-5, "1234" at 7-10`,
+		"sequence":      `Contains sequence "1234" at 7-10`,
+		"repetition":    `Contains repetition "zzzz" at 12-15`,
+	}
+	for _, check := range result.Checks {
+		if message, ok := want[check.Name]; ok {
+			if check.Passed || check.Message != message || len(check.Findings) == 0 {
+				t.Errorf("Check %s = %+v, want failed with %q", check.Name, check, message)
+			}
+			delete(want, check.Name)
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("Missing checks %v", want)
+	}
+
+	result, _ = Analyze("Xqwe!123!zzz", criteria)
+	if !result.Passed {
+		t.Errorf("Expected patterns of 3 characters to pass, got %+v", result.Checks)
+	}
+}
@@ -0,0 +1,306 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package analyzer
+
+import (
+	"hash/maphash"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Kinds of ReuseGroup, from the closest to the loosest relation
+const (
+	ReuseExact   = "exact"
+	ReuseCase    = "case"
+	ReuseSimilar = "similar"
+)
+
+// DefaultReuseDistance is the largest edit distance between near-duplicates
+// by default, and MaxReuseDistance the largest supported
+const (
+	DefaultReuseDistance = 2
+	MaxReuseDistance     = 3
+)
+
+// MinSimilarLength is the length of the shortest password compared by edit
+// distance, and MaxSimilarLength of the longest. Short passwords are within a
+// few edits of each other by chance, long ones are costly to compare.
+const (
+	MinSimilarLength = 6
+	MaxSimilarLength = 64
+)
+
+// maxIndexedLength is the length of the longest password in the deletion
+// index. The number of deletions grows with the length to the power of the
+// distance, so longer passwords are compared pairwise with the passwords of
+// similar length instead.
+const maxIndexedLength = 20
+
+// MinBaseWordLength is the length of the shortest base word linking
+// passwords, such as "summer" in Summer2023! and Summer2024!
+const MinBaseWordLength = 4
+
+// ReuseGroup is a group of passwords of a batch that are the same or alike
+type ReuseGroup struct {
+	// Kind is ReuseExact for identical passwords, ReuseCase for passwords
+	// that only differ in case and ReuseSimilar for near-duplicates
+	Kind string `json:"kind"`
+
+	// Lines are the line numbers of the passwords, in ascending order
+	Lines []int `json:"lines"`
+
+	// Passwords are the distinct passwords of the group in input order
+	Passwords []string `json:"passwords,omitempty"`
+
+	// BaseWord is the base word shared by similar passwords, empty when they
+	// are only linked by edit distance
+	BaseWord string `json:"base_word,omitempty"`
+}
+
+// ReuseDetector finds reused passwords across a batch. Passwords are added
+// one by one with their line number; unlike the other checks, the detector
+// holds every distinct password in memory until Groups is called.
+type ReuseDetector struct {
+	// MaxDistance is the largest edit distance between similar passwords,
+	// 0 links similar passwords by base word only. Larger values than
+	// MaxReuseDistance are lowered to it.
+	MaxDistance int
+
+	lines map[string][]int
+	order []string
+}
+
+// NewReuseDetector returns a detector of passwords up to maxDistance edits apart
+func NewReuseDetector(maxDistance int) *ReuseDetector {
+	return &ReuseDetector{MaxDistance: maxDistance, lines: make(map[string][]int)}
+}
+
+// Add adds the password found on the given line
+func (d *ReuseDetector) Add(line int, password string) {
+	if _, ok := d.lines[password]; !ok {
+		d.order = append(d.order, password)
+	}
+	d.lines[password] = append(d.lines[password], line)
+}
+
+// Groups returns the groups of reused passwords: exact duplicates, then
+// passwords equal ignoring case, then similar passwords. A password may be
+// part of a group of every kind; similar groups include the duplicates of
+// their passwords.
+func (d *ReuseDetector) Groups() []ReuseGroup {
+	var groups []ReuseGroup
+	for _, password := range d.order {
+		if len(d.lines[password]) > 1 {
+			groups = append(groups, ReuseGroup{Kind: ReuseExact, Lines: d.lines[password], Passwords: []string{password}})
+		}
+	}
+
+	// Passwords by case-folded form, in input order
+	var folded []string
+	variants := make(map[string][]string)
+	for _, password := range d.order {
+		key := strings.ToLower(Normalize(password))
+		if _, ok := variants[key]; !ok {
+			folded = append(folded, key)
+		}
+		variants[key] = append(variants[key], password)
+	}
+	for _, key := range folded {
+		if len(variants[key]) > 1 {
+			groups = append(groups, d.group(ReuseCase, variants[key]))
+		}
+	}
+
+	sets := d.similarSets(folded)
+	for _, set := range sets {
+		var passwords []string
+		word := baseWord(folded[set[0]])
+		for _, i := range set {
+			passwords = append(passwords, variants[folded[i]]...)
+			if word != baseWord(folded[i]) {
+				word = ""
+			}
+		}
+		group := d.group(ReuseSimilar, passwords)
+		group.BaseWord = word
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// group returns a group of the given kind with the lines of the passwords
+func (d *ReuseDetector) group(kind string, passwords []string) ReuseGroup {
+	group := ReuseGroup{Kind: kind, Passwords: passwords}
+	for _, password := range passwords {
+		group.Lines = append(group.Lines, d.lines[password]...)
+	}
+	sort.Ints(group.Lines)
+	return group
+}
+
+// similarSets links the case-folded passwords sharing a base word or within
+// MaxDistance edits and returns the sets of two or more, as indexes into
+// folded in input order. Candidates for the edit distance are found through
+// an index of the strings left after deleting up to MaxDistance characters:
+// two strings within d edits share such a string, so most pairs are never
+// compared. Passwords longer than maxIndexedLength are compared with every
+// password of a length within reach instead.
+func (d *ReuseDetector) similarSets(folded []string) [][]int {
+	parent := make([]int, len(folded))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(i, j int) {
+		if i, j = find(i), find(j); i != j {
+			parent[max(i, j)] = min(i, j)
+		}
+	}
+
+	// Deletions are indexed by hash, a collision only costs a comparison.
+	// Passwords too long for the index are kept by length, along with the
+	// indexed ones they may be within distance of.
+	distance := min(d.MaxDistance, MaxReuseDistance)
+	seed := maphash.MakeSeed()
+	byBaseWord := make(map[string]int)
+	byDeletion := make(map[uint64][]int)
+	byLength := make(map[int][]int)
+	for i, key := range folded {
+		if word := baseWord(key); word != "" {
+			if j, ok := byBaseWord[word]; ok {
+				union(i, j)
+			} else {
+				byBaseWord[word] = i
+			}
+		}
+
+		runes := []rune(key)
+		if distance <= 0 || len(runes) < MinSimilarLength || len(runes) > MaxSimilarLength {
+			continue
+		}
+		compared := make(map[int]bool)
+		compare := func(j int) {
+			if compared[j] || find(i) == find(j) {
+				return
+			}
+			compared[j] = true
+			if editDistance(runes, []rune(folded[j]), distance) <= distance {
+				union(i, j)
+			}
+		}
+
+		for length := len(runes) - distance; length <= len(runes)+distance; length++ {
+			if length > maxIndexedLength || len(runes) > maxIndexedLength {
+				for _, j := range byLength[length] {
+					compare(j)
+				}
+			}
+		}
+		if len(runes) > maxIndexedLength-distance {
+			byLength[len(runes)] = append(byLength[len(runes)], i)
+		}
+		if len(runes) > maxIndexedLength {
+			continue
+		}
+		for _, deletion := range deletions(runes, distance) {
+			hash := maphash.String(seed, deletion)
+			for _, j := range byDeletion[hash] {
+				compare(j)
+			}
+			byDeletion[hash] = append(byDeletion[hash], i)
+		}
+	}
+
+	members := make(map[int][]int)
+	var roots []int
+	for i := range folded {
+		root := find(i)
+		if len(members[root]) == 0 {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], i)
+	}
+	var sets [][]int
+	for _, root := range roots {
+		if len(members[root]) > 1 {
+			sets = append(sets, members[root])
+		}
+	}
+	return sets
+}
+
+// baseWord returns the longest run of letters of a case-folded password, or
+// "" when it is shorter than MinBaseWordLength
+func baseWord(password string) string {
+	word := ""
+	for _, run := range strings.FieldsFunc(password, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if len([]rune(run)) > len([]rune(word)) {
+			word = run
+		}
+	}
+	if len([]rune(word)) < MinBaseWordLength {
+		return ""
+	}
+	return word
+}
+
+// deletions returns the distinct strings left after deleting up to n runes,
+// including the unchanged string
+func deletions(runes []rune, n int) []string {
+	seen := map[string]bool{string(runes): true}
+	result := []string{string(runes)}
+	level := [][]rune{runes}
+	for ; n > 0; n-- {
+		var next [][]rune
+		for _, current := range level {
+			for i := range current {
+				deleted := append(append([]rune{}, current[:i]...), current[i+1:]...)
+				if key := string(deleted); !seen[key] {
+					seen[key] = true
+					result = append(result, key)
+					next = append(next, deleted)
+				}
+			}
+		}
+		level = next
+	}
+	return result
+}
+
+// editDistance returns the Levenshtein distance between a and b, or limit+1
+// once it is known to exceed limit
+func editDistance(a, b []rune, limit int) int {
+	if absInt(len(a)-len(b)) > limit {
+		return limit + 1
+	}
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			rowMin = min(rowMin, current[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
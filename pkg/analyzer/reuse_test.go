@@ -0,0 +1,98 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReuseDetector(t *testing.T) {
+	passwords := []string{
+		"Summer2023!",  // 1
+		"kX9#vQ2mLp7z", // 2
+		"Summer2024!",  // 3
+		"letmein",      // 4
+		"kX9#vQ2mLp7z", // 5
+		"LetMeIn",      // 6
+		"tR7$wq1Bn0xe", // 7
+		"tR7$wq1Bn0xf", // 8
+		"abc1",         // 9
+		"abc2",         // 10
+	}
+	detector := NewReuseDetector(DefaultReuseDistance)
+	for i, password := range passwords {
+		detector.Add(i+1, password)
+	}
+
+	var got []string
+	for _, group := range detector.Groups() {
+		got = append(got, fmt.Sprintf("%s %v %q %s", group.Kind, group.Lines, group.Passwords, group.BaseWord))
+	}
+	want := []string{
+		`exact [2 5] ["kX9#vQ2mLp7z"] `,
+		`case [4 6] ["letmein" "LetMeIn"] `,
+		`similar [1 3] ["Summer2023!" "Summer2024!"] summer`,
+		`similar [7 8] ["tR7$wq1Bn0xe" "tR7$wq1Bn0xf"] `,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Groups() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestReuseDetectorBaseWordOnly(t *testing.T) {
+	detector := NewReuseDetector(0)
+	for i, password := range []string{"Winter!01", "Winter!02", "Wintre!01", "w1nter"} {
+		detector.Add(i+1, password)
+	}
+
+	groups := detector.Groups()
+	if len(groups) != 1 || fmt.Sprint(groups[0].Lines) != "[1 2]" || groups[0].BaseWord != "winter" {
+		t.Errorf("Groups() = %+v, want lines 1 and 2 linked by winter", groups)
+	}
+}
+
+func TestReuseDetectorLong(t *testing.T) {
+	long := strings.Repeat("q7#Lx", 12) // 60 characters, beyond the deletion index
+	passwords := []string{
+		long,                              // 1
+		"Zq8!" + long[4:] + "W",           // 2, 4 edits from line 1
+		long[:50] + "Zq8" + long[53:],     // 3, 3 edits from line 1
+		"vB4$mN7^pK2&wR9*tY5",             // 4, indexed
+		"vB4$mN7^pK2&wR9*tY5@j",           // 5, too long for the index, 2 edits from line 4
+		strings.Repeat("hG3%", 16),        // 6
+		strings.Repeat("hG3%", 15) + "hG", // 7, 2 edits from line 6
+	}
+	detector := NewReuseDetector(10)
+	for i, password := range passwords {
+		detector.Add(i+1, password)
+	}
+
+	var got []string
+	for _, group := range detector.Groups() {
+		got = append(got, fmt.Sprintf("%s %v", group.Kind, group.Lines))
+	}
+	want := []string{"similar [1 3]", "similar [4 5]", "similar [6 7]"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Groups() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{"kitten", "sitting", 5, 3},
+		{"kitten", "sitting", 2, 3},
+		{"same", "same", 2, 0},
+		{"", "abc", 5, 3},
+		{"Ünï", "Uni", 5, 2},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance([]rune(tt.a), []rune(tt.b), tt.limit); got != tt.want {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package analyzer
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultStatsTop is the number of masks, base words, prefixes, suffixes and
+// years a StatsReport lists by default
+const DefaultStatsTop = 10
+
+// MinStatsShare is the number of passwords a base word, prefix, suffix or
+// year must be found in to be listed, so that a report never quotes a part
+// of a single password
+const MinStatsShare = 2
+
+// Compositions of passwords by the character classes they contain, named as
+// by the statsgen tool of PACK. Letters without case count as lowercase.
+const (
+	CompositionNumeric              = "numeric"
+	CompositionSpecial              = "special"
+	CompositionSpecialNum           = "specialnum"
+	CompositionLowerAlpha           = "loweralpha"
+	CompositionUpperAlpha           = "upperalpha"
+	CompositionMixedAlpha           = "mixedalpha"
+	CompositionLowerAlphaNum        = "loweralphanum"
+	CompositionUpperAlphaNum        = "upperalphanum"
+	CompositionMixedAlphaNum        = "mixedalphanum"
+	CompositionLowerAlphaSpecial    = "loweralphaspecial"
+	CompositionUpperAlphaSpecial    = "upperalphaspecial"
+	CompositionMixedAlphaSpecial    = "mixedalphaspecial"
+	CompositionLowerAlphaSpecialNum = "loweralphaspecialnum"
+	CompositionUpperAlphaSpecialNum = "upperalphaspecialnum"
+	CompositionAll                  = "all"
+)
+
+// Stats aggregates the passwords of a corpus into the metrics of a
+// StatsReport, like the PACK and pipal tools. Add and AddScore may be called
+// from different goroutines, such as StreamOptions.Observe and the emit
+// function of AnalyzeStream. Only the counts are held in memory, one per
+// distinct mask, base word, prefix, suffix and year.
+type Stats struct {
+	mu           sync.Mutex
+	total        int
+	lengths      map[int]int
+	compositions map[string]int
+	masks        map[string]int
+	baseWords    map[string]int
+	prefixes     map[string]int
+	suffixes     map[string]int
+	years        map[string]int
+	scores       [5]int
+}
+
+// NewStats returns empty statistics
+func NewStats() *Stats {
+	return &Stats{
+		lengths:      make(map[int]int),
+		compositions: make(map[string]int),
+		masks:        make(map[string]int),
+		baseWords:    make(map[string]int),
+		prefixes:     make(map[string]int),
+		suffixes:     make(map[string]int),
+		years:        make(map[string]int),
+	}
+}
+
+// Add counts a password, normalized with Normalize. Empty passwords are
+// skipped.
+func (s *Stats) Add(password string) {
+	password = Normalize(password)
+	if password == "" {
+		return
+	}
+	folded := strings.ToLower(password)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.total++
+	s.lengths[utf8.RuneCountInString(password)]++
+	s.compositions[Composition(password)]++
+	s.masks[Mask(password)]++
+	if word := baseWord(folded); word != "" {
+		s.baseWords[word]++
+	}
+
+	// Prefixes and suffixes are the runs of digits and symbols around the
+	// letters, such as the 2024! of Summer2024!
+	if first := strings.IndexFunc(folded, unicode.IsLetter); first >= 0 {
+		last := strings.LastIndexFunc(folded, unicode.IsLetter)
+		_, size := utf8.DecodeRuneInString(folded[last:])
+		if first > 0 {
+			s.prefixes[folded[:first]]++
+		}
+		if suffix := folded[last+size:]; suffix != "" {
+			s.suffixes[suffix]++
+		}
+	}
+
+	for _, digits := range strings.FieldsFunc(password, func(r rune) bool { return r < '0' || r > '9' }) {
+		if year, _ := strconv.Atoi(digits); len(digits) == 4 && year >= 1900 && year <= 2099 {
+			s.years[digits]++
+		}
+	}
+}
+
+// AddScore counts the strength score of a password, from 0 to 4
+func (s *Stats) AddScore(score int) {
+	if score < 0 || score >= len(s.scores) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scores[score]++
+}
+
+// StatsReport holds the metrics of a corpus
+type StatsReport struct {
+	// Total is the number of passwords counted
+	Total int `json:"total"`
+
+	// Lengths is the histogram of the lengths in characters, by length
+	Lengths []LengthCount `json:"lengths"`
+
+	// Compositions counts the passwords by the character classes they
+	// contain, such as CompositionLowerAlphaNum, most frequent first
+	Compositions []StatsCount `json:"compositions"`
+
+	// Masks are the most frequent masks in hashcat notation
+	Masks []StatsCount `json:"masks"`
+
+	// BaseWords are the most frequent base words, case-folded
+	BaseWords []StatsCount `json:"base_words"`
+
+	// Prefixes and Suffixes are the most frequent runs of digits and
+	// symbols before the first and after the last letter
+	Prefixes []StatsCount `json:"prefixes"`
+	Suffixes []StatsCount `json:"suffixes"`
+
+	// Years are the most frequent years from 1900 to 2099
+	Years []StatsCount `json:"years"`
+
+	// Scores is the distribution of the strength scores, from 0 to 4
+	Scores []ScoreCount `json:"scores"`
+}
+
+// StatsCount is the number of passwords with a value
+type StatsCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// LengthCount is the number of passwords of a length
+type LengthCount struct {
+	Length int `json:"length"`
+	Count  int `json:"count"`
+}
+
+// ScoreCount is the number of passwords of a strength score
+type ScoreCount struct {
+	Score int    `json:"score"`
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// Report returns the metrics counted so far, with up to top masks, base
+// words, prefixes, suffixes and years. Base words, prefixes, suffixes and
+// years found in fewer than MinStatsShare passwords are left out.
+func (s *Stats) Report(top int) StatsReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := StatsReport{
+		Total:        s.total,
+		Lengths:      []LengthCount{},
+		Compositions: topCounts(s.compositions, len(s.compositions), 1),
+		Masks:        topCounts(s.masks, top, 1),
+		BaseWords:    topCounts(s.baseWords, top, MinStatsShare),
+		Prefixes:     topCounts(s.prefixes, top, MinStatsShare),
+		Suffixes:     topCounts(s.suffixes, top, MinStatsShare),
+		Years:        topCounts(s.years, top, MinStatsShare),
+	}
+	for length, count := range s.lengths {
+		report.Lengths = append(report.Lengths, LengthCount{Length: length, Count: count})
+	}
+	sort.Slice(report.Lengths, func(i, j int) bool { return report.Lengths[i].Length < report.Lengths[j].Length })
+	for score, count := range s.scores {
+		report.Scores = append(report.Scores, ScoreCount{Score: score, Label: scoreLabels[score], Count: count})
+	}
+	return report
+}
+
+// topCounts returns up to top values counted at least min times, most
+// frequent first and in order of value between equal counts
+func topCounts(counts map[string]int, top, min int) []StatsCount {
+	result := []StatsCount{}
+	for value, count := range counts {
+		if count >= min {
+			result = append(result, StatsCount{Value: value, Count: count})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Value < result[j].Value
+	})
+	if len(result) > top {
+		result = result[:top]
+	}
+	return result
+}
+
+// Mask returns the mask of a password in hashcat notation, ?u for an
+// uppercase letter, ?l for another letter, ?d for a digit and ?s for any
+// other character, e.g. ?u?l?l?l?d?d for Pass12. Letters and digits of any
+// script are classified like their ASCII counterparts.
+func Mask(password string) string {
+	var b strings.Builder
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			b.WriteString("?u")
+		case unicode.IsLetter(char):
+			b.WriteString("?l")
+		case unicode.IsDigit(char):
+			b.WriteString("?d")
+		default:
+			b.WriteString("?s")
+		}
+	}
+	return b.String()
+}
+
+// Composition returns the composition of a password, such as
+// CompositionMixedAlphaNum for Pass12
+func Composition(password string) string {
+	var upper, lower, digit, special bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			upper = true
+		case unicode.IsLetter(char):
+			lower = true
+		case unicode.IsDigit(char):
+			digit = true
+		default:
+			special = true
+		}
+	}
+
+	var name string
+	switch {
+	case upper && lower:
+		name = "mixedalpha"
+	case upper:
+		name = "upperalpha"
+	case lower:
+		name = "loweralpha"
+	}
+	if special {
+		name += "special"
+	}
+	if digit {
+		name += "num"
+	}
+	switch name {
+	case "num":
+		return CompositionNumeric
+	case "mixedalphaspecialnum":
+		return CompositionAll
+	}
+	return name
+}
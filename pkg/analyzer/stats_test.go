@@ -0,0 +1,87 @@
+package analyzer
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		password string
+		want     string
+	}{
+		{"Pass12", "?u?l?l?l?d?d"},
+		{"a b!", "?l?s?l?s"},
+		{"Ünï٣€", "?u?l?l?d?s"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Mask(tt.password); got != tt.want {
+			t.Errorf("Mask(%q) = %q, want %q", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestComposition(t *testing.T) {
+	tests := []struct {
+		password string
+		want     string
+	}{
+		{"123456", CompositionNumeric},
+		{"!!!", CompositionSpecial},
+		{"12!", CompositionSpecialNum},
+		{"password", CompositionLowerAlpha},
+		{"PASSWORD", CompositionUpperAlpha},
+		{"Password", CompositionMixedAlpha},
+		{"password1", CompositionLowerAlphaNum},
+		{"PASSWORD1", CompositionUpperAlphaNum},
+		{"Password1", CompositionMixedAlphaNum},
+		{"pass!", CompositionLowerAlphaSpecial},
+		{"PASS!", CompositionUpperAlphaSpecial},
+		{"Pass!", CompositionMixedAlphaSpecial},
+		{"pass1!", CompositionLowerAlphaSpecialNum},
+		{"PASS1!", CompositionUpperAlphaSpecialNum},
+		{"Pass1!", CompositionAll},
+		{"密码2024", CompositionLowerAlphaNum},
+	}
+
+	for _, tt := range tests {
+		if got := Composition(tt.password); got != tt.want {
+			t.Errorf("Composition(%q) = %q, want %q", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	stats := NewStats()
+	for _, password := range []string{"Summer2024!", "summer2023", "Winter2024!", "!!2024abc", "1999Spring", "#1Spring", "123456", "kX9#vQ2mLp7z", ""} {
+		stats.Add(password)
+	}
+	for _, score := range []int{0, 0, 1, 4, 5} {
+		stats.AddScore(score)
+	}
+
+	report := stats.Report(2)
+	if report.Total != 8 {
+		t.Errorf("Total = %d, want 8", report.Total)
+	}
+	tests := []struct {
+		name string
+		got  any
+		want string
+	}{
+		{"Lengths", report.Lengths, "[{6 1} {8 1} {9 1} {10 2} {11 2} {12 1}]"},
+		{"Masks", report.Masks, "[{?u?l?l?l?l?l?d?d?d?d?s 2} {?d?d?d?d?d?d 1}]"},
+		{"BaseWords", report.BaseWords, "[{spring 2} {summer 2}]"},
+		{"Prefixes", report.Prefixes, "[]"},
+		{"Suffixes", report.Suffixes, "[{2024! 2}]"},
+		{"Years", report.Years, "[{2024 3}]"},
+		{"Scores", report.Scores, "[{0 very weak 2} {1 weak 1} {2 fair 0} {3 strong 0} {4 very strong 1}]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(tt.got); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
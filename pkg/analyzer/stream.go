@@ -0,0 +1,221 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package analyzer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// MaxLineLength is the longest line AnalyzeStream accepts
+const MaxLineLength = 1 << 20
+
+// StreamOptions controls AnalyzeStream
+type StreamOptions struct {
+	// Workers is the number of passwords analyzed concurrently, GOMAXPROCS when 0
+	Workers int
+
+	// Progress is called with the number of bytes read from the input so far.
+	// It is called from the reading goroutine and must be cheap.
+	Progress func(bytesRead int64)
+
+	// Format is the format of r, one of InputFormats, FormatLines when empty.
+	//
+	// FormatCSV reads CSV with a header row. The column named password, or
+	// PasswordColumn when set, holds the passwords, the other columns of a row,
+	// such as username or email, are added to the context terms of its
+	// password. The exports of the password managers of InputFormats are
+	// detected from their header. Passwords are not trimmed, rows without one
+	// are skipped.
+	//
+	// The other formats read the exports of password managers, adding the
+	// title and username of an entry to the context terms of its password.
+	// Results of every format but FormatLines carry the Entry of the password.
+	Format         string
+	PasswordColumn string
+
+	// Observe is called with every password read and the line it is on, in
+	// input order, such as to add it to a ReuseDetector. It is called from the
+	// reading goroutine; AnalyzeStream returns nil only once it has been
+	// called for every password.
+	Observe func(line int, password string)
+
+	// Show is called from the workers with every password, and what it
+	// returns is set as Result.Shown, such as a masked form identifying the
+	// password in a report
+	Show func(password string) string
+}
+
+// AnalyzeStream analyzes the passwords of r, one per line, with a pool of
+// workers. emit is called for every password in input order with its 1-based
+// index among the non-empty lines. Surrounding whitespace is trimmed as by
+// ReadPasswords. With opts.Format, the index counts the entries with a
+// password.
+//
+// Only a few passwords per worker are held in memory at any time, so inputs of
+// any size are analyzed in constant memory. The first error returned by emit,
+// by the breach checker or by reading r stops the analysis and is returned.
+func AnalyzeStream(r io.Reader, criteria Criteria, opts StreamOptions, emit func(index int, result Result) error) error {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	format := opts.Format
+	if format == "" {
+		format = FormatLines
+	}
+	if !slices.Contains(InputFormats, format) {
+		return fmt.Errorf("unknown input format %q, expected one of: %s", format, strings.Join(InputFormats, ", "))
+	}
+
+	type outcome struct {
+		result Result
+		err    error
+	}
+	type job struct {
+		password string
+		entry    *Entry
+		criteria Criteria
+		outcome  chan outcome
+	}
+
+	// pending holds the outcome of every password in input order. Its capacity
+	// bounds the passwords in flight.
+	jobs := make(chan job)
+	pending := make(chan chan outcome, 4*workers)
+	done := make(chan struct{})
+
+	// Workers still analyzing when the analysis stops are waited for, so that
+	// criteria.Breach is no longer in use once AnalyzeStream returns
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer close(done)
+
+	var readErr error
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+
+		counter := &countingReader{r: r}
+		var read func() (streamRecord, error)
+		switch format {
+		case FormatLines:
+			read = lineReader(counter)
+		case FormatBitwardenJSON:
+			read = bitwardenReader(counter)
+		case FormatKeePassXML:
+			read = keepassReader(counter)
+		default:
+			read = csvReader(counter, format, opts.PasswordColumn)
+		}
+		for {
+			record, err := read()
+			if err != nil {
+				if err != io.EOF {
+					readErr = err
+				}
+				return
+			}
+			if record.password == "" {
+				continue
+			}
+			if opts.Observe != nil {
+				opts.Observe(record.entry.Line, record.password)
+			}
+
+			next := job{password: record.password, criteria: criteria, outcome: make(chan outcome, 1)}
+			if format != FormatLines {
+				next.entry = &record.entry
+				// Clipped, so that entries do not share the backing array of
+				// their terms. Entries without terms still run the context
+				// check, so that every result has the same checks.
+				next.criteria.ContextTerms = append(slices.Clip(criteria.ContextTerms), ContextTerms(record.context...)...)
+				if next.criteria.ContextTerms == nil {
+					next.criteria.ContextTerms = []string{}
+				}
+			}
+			select {
+			case pending <- next.outcome:
+			case <-done:
+				return
+			}
+			select {
+			case jobs <- next:
+			case <-done:
+				return
+			}
+
+			if opts.Progress != nil {
+				opts.Progress(counter.n.Load())
+			}
+		}
+	}()
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				result, err := Analyze(job.password, job.criteria)
+				result.Entry = job.entry
+				if opts.Show != nil {
+					result.Shown = opts.Show(job.password)
+				}
+				job.outcome <- outcome{result, err}
+			}
+		}()
+	}
+
+	index := 0
+	for next := range pending {
+		outcome := <-next
+		if outcome.err != nil {
+			return outcome.err
+		}
+		index++
+		if err := emit(index, outcome.result); err != nil {
+			return err
+		}
+	}
+	return readErr
+}
+
+// lineReader returns the trimmed lines of r one by one with their 1-based
+// line number, and io.EOF at the end
+func lineReader(r io.Reader) func() (streamRecord, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), MaxLineLength)
+	line := 0
+	return func() (streamRecord, error) {
+		if scanner.Scan() {
+			line++
+			return streamRecord{entry: Entry{Line: line}, password: strings.TrimSpace(scanner.Text())}, nil
+		}
+		if err := scanner.Err(); err == bufio.ErrTooLong {
+			return streamRecord{}, fmt.Errorf("line %d longer than %d bytes", line+1, MaxLineLength)
+		} else if err != nil {
+			return streamRecord{}, err
+		}
+		return streamRecord{}, io.EOF
+	}
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,240 @@
+package analyzer
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeStreamOrder(t *testing.T) {
+	var input strings.Builder
+	var want []string
+	for i := 0; i < 500; i++ {
+		password := fmt.Sprintf("Password%d!", i)
+		want = append(want, password)
+		fmt.Fprintf(&input, "  %s  \r\n\n", password)
+	}
+
+	var progress int64
+	opts := StreamOptions{Workers: 8, Progress: func(bytesRead int64) { progress = bytesRead }}
+	var got []string
+	err := AnalyzeStream(strings.NewReader(input.String()), Criteria{MinLength: 8}, opts, func(index int, result Result) error {
+		if index != len(got)+1 {
+			t.Fatalf("index = %d, want %d", index, len(got)+1)
+		}
+		got = append(got, want[index-1])
+		if result.Length != len(want[index-1]) {
+			t.Errorf("password %d: length = %d, want %d", index, result.Length, len(want[index-1]))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Errorf("emitted %d passwords, want %d", len(got), len(want))
+	}
+	if progress != int64(input.Len()) {
+		t.Errorf("progress = %d, want %d", progress, input.Len())
+	}
+}
+
+func TestAnalyzeStreamEmitError(t *testing.T) {
+	input := strings.Repeat("Password1\n", 1000)
+	stop := errors.New("stop")
+
+	emitted := 0
+	err := AnalyzeStream(strings.NewReader(input), Criteria{}, StreamOptions{Workers: 4}, func(index int, result Result) error {
+		emitted++
+		if index == 10 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("err = %v, want %v", err, stop)
+	}
+	if emitted != 10 {
+		t.Errorf("emitted %d passwords, want 10", emitted)
+	}
+}
+
+func TestAnalyzeStreamLongLine(t *testing.T) {
+	input := "Password1\n" + strings.Repeat("a", MaxLineLength+1) + "\n"
+	err := AnalyzeStream(strings.NewReader(input), Criteria{}, StreamOptions{}, func(int, Result) error { return nil })
+	if err == nil {
+		t.Error("Expected an error for a line longer than MaxLineLength")
+	}
+}
+
+// benchmarkLines are the passwords repeated by passwordReader
+var benchmarkLines = []byte("password\nP@ssw0rd2024\nkX9#vQ2mLp7z\ncorrect horse battery staple\nqwerty123\nTr0ub4dor&3\n")
+
+// passwordReader generates size bytes of passwords without holding them in memory
+type passwordReader struct {
+	size   int64
+	offset int64
+}
+
+func (p *passwordReader) Read(b []byte) (int, error) {
+	if p.offset >= p.size {
+		return 0, io.EOF
+	}
+	if remaining := p.size - p.offset; int64(len(b)) > remaining {
+		b = b[:remaining]
+	}
+	n := 0
+	for n < len(b) {
+		n += copy(b[n:], benchmarkLines[(p.offset+int64(n))%int64(len(benchmarkLines)):])
+	}
+	p.offset += int64(n)
+	return n, nil
+}
+
+// BenchmarkAnalyzeStream reports the throughput of analyzing generated input.
+// Set PASSWORD_ZEN_BENCH_FILE to measure a real file instead, for example a
+// multi-GB audit dump:
+//
+//	PASSWORD_ZEN_BENCH_FILE=dump.txt go test ./pkg/analyzer -run '^$' -bench AnalyzeStream -benchtime 1x
+func BenchmarkAnalyzeStream(b *testing.B) {
+	criteria := Criteria{MinLength: 12, MinDigits: 1, MinUpper: 1, MinLower: 1, MinScore: 3}
+	emit := func(int, Result) error { return nil }
+
+	if path := os.Getenv("PASSWORD_ZEN_BENCH_FILE"); path != "" {
+		info, err := os.Stat(path)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.SetBytes(info.Size())
+		for i := 0; i < b.N; i++ {
+			file, err := os.Open(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			err = AnalyzeStream(file, criteria, StreamOptions{}, emit)
+			file.Close()
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+		return
+	}
+
+	for _, workers := range []int{1, 4, 0} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			const size = 64 << 10
+			b.SetBytes(size)
+			for i := 0; i < b.N; i++ {
+				if err := AnalyzeStream(&passwordReader{size: size}, criteria, StreamOptions{Workers: workers}, emit); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// benchmarkSize is the input size of BenchmarkAnalyzeStreamLarge in bytes
+var benchmarkSize = flag.Int64("bench-size", 0, "input size of BenchmarkAnalyzeStreamLarge in bytes, 0 grows it with b.N")
+
+// BenchmarkAnalyzeStreamLarge reports the throughput of analyzing one large
+// generated input with a worker per CPU. The input grows by 1 KiB per op, so
+// -benchtime sets how much is analyzed; -bench-size analyzes a fixed size in
+// every op instead, for example a 4 GiB dump:
+//
+//	go test ./pkg/analyzer -run '^$' -bench AnalyzeStreamLarge -benchtime 1x -bench-size 4294967296
+func BenchmarkAnalyzeStreamLarge(b *testing.B) {
+	criteria := Criteria{MinLength: 12, MinDigits: 1, MinUpper: 1, MinLower: 1, MinScore: 3}
+	emit := func(int, Result) error { return nil }
+
+	size, runs := int64(b.N)<<10, 1
+	b.SetBytes(1 << 10)
+	if *benchmarkSize > 0 {
+		size, runs = *benchmarkSize, b.N
+		b.SetBytes(size)
+	}
+	b.ResetTimer()
+	for i := 0; i < runs; i++ {
+		if err := AnalyzeStream(&passwordReader{size: size}, criteria, StreamOptions{}, emit); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestAnalyzeStreamCSV(t *testing.T) {
+	input := "\ufeffusername,Password,email\n" +
+		"bob,B0b!secure99,bob@corp.io\n" +
+		"carol,,carol@corp.io\n" +
+		"dave,\"kX9#,vQ2mLp7z\",dave@corp.io\n" +
+		"erin,corp-Erin!,erin@corp.io\n"
+
+	criteria := Criteria{ContextTerms: []string{"acme"}}
+	var got []string
+	err := AnalyzeStream(strings.NewReader(input), criteria, StreamOptions{Format: FormatCSV}, func(index int, result Result) error {
+		for _, check := range result.Checks {
+			if check.Name == "context" {
+				got = append(got, strconv.FormatBool(check.Passed))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Rows without a password are skipped, corp is the domain of every row
+	if want := "false,true,false"; strings.Join(got, ",") != want {
+		t.Errorf("context checks passed = %s, want %s", strings.Join(got, ","), want)
+	}
+	if len(criteria.ContextTerms) != 1 {
+		t.Errorf("Rows changed the shared terms: %v", criteria.ContextTerms)
+	}
+
+	err = AnalyzeStream(strings.NewReader("username,secret\nbob,x\n"), criteria, StreamOptions{Format: FormatCSV}, func(int, Result) error { return nil })
+	if err == nil {
+		t.Error("Expected an error for a CSV without password column")
+	}
+}
+
+func TestAnalyzeStreamObserve(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		format string
+		want   string
+	}{
+		{"Lines", "first\n\n  second \nthird\n", FormatLines, "1:first 3:second 4:third"},
+		// The quoted note of bob spans two lines
+		{"CSV", "username,notes,password\nalice,,one\nbob,\"a\nb\",two\ncarol,,three\n", FormatCSV, "2:one 4:two 5:three"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			opts := StreamOptions{Format: tt.format, Observe: func(line int, password string) {
+				got = append(got, fmt.Sprintf("%d:%s", line, password))
+			}}
+			if err := AnalyzeStream(strings.NewReader(tt.input), Criteria{}, opts, func(int, Result) error { return nil }); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("Observed %q, want %q", strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeStreamShow(t *testing.T) {
+	var got []string
+	opts := StreamOptions{Workers: 4, Show: strings.ToUpper}
+	err := AnalyzeStream(strings.NewReader("first\nsecond\nthird\n"), Criteria{}, opts, func(index int, result Result) error {
+		got = append(got, result.Shown)
+		return nil
+	})
+	if err != nil || strings.Join(got, " ") != "FIRST SECOND THIRD" {
+		t.Errorf("Shown = %q, %v", got, err)
+	}
+}
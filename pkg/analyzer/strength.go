@@ -0,0 +1,599 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// The strength estimator follows the approach of zxcvbn: the password is split
+// into the sequence of known patterns (dictionary words, keyboard walks,
+// sequences, repeats, dates) and random characters that needs the fewest
+// guesses, and the guesses of that sequence estimate how hard it is to crack.
+
+// Guess estimation constants
+const (
+	bruteforceCardinality           = 10
+	minGuessesBeforeGrowingSequence = 10000
+	minSubmatchGuessesSingleChar    = 10
+	minSubmatchGuessesMultiChar     = 50
+	minYearSpace                    = 20
+)
+
+// maxMatchedLength is the number of characters searched for patterns, as in
+// zxcvbn. The rest of a longer password counts as random characters, which
+// keeps the estimate fast for long inputs.
+const maxMatchedLength = 100
+
+// maxGuesses caps the guesses of matches and sequences, so that guesses,
+// entropy and crack times stay finite for very long passwords
+const maxGuesses = 1e300
+
+// referenceYear is the year used to judge how guessable years and dates are
+var referenceYear = time.Now().Year()
+
+// Score labels indexed by score
+var scoreLabels = []string{"very weak", "weak", "fair", "strong", "very strong"}
+
+// crackTime is the estimated time to guess a password under an attack scenario
+type crackTime struct {
+	scenario string
+	seconds  float64
+}
+
+// Attack scenarios in guesses per second
+var attackScenarios = []struct {
+	name string
+	rate float64
+}{
+	{"online, throttled (100/hour)", 100.0 / 3600},
+	{"online, unthrottled (10/second)", 10},
+	{"offline, slow hash (1e4/second)", 1e4},
+	{"offline, fast hash (1e10/second)", 1e10},
+}
+
+// strengthResult is the outcome of estimating a password's strength
+type strengthResult struct {
+	guesses    float64
+	entropy    float64
+	score      int
+	crackTimes []crackTime
+	sequence   []*strengthMatch
+}
+
+// Strength is the strength estimate of a password
+type Strength struct {
+	// Score ranges from 0 (very weak) to 4 (very strong)
+	Score       int         `json:"score"`
+	Label       string      `json:"label"`
+	Guesses     float64     `json:"guesses"`
+	EntropyBits float64     `json:"entropy_bits"`
+	CrackTimes  []CrackTime `json:"crack_times"`
+	Patterns    []Pattern   `json:"patterns"`
+}
+
+// CrackTime is the estimated time to crack a password in one attack scenario
+type CrackTime struct {
+	Scenario string  `json:"scenario"`
+	Seconds  float64 `json:"seconds"`
+	Display  string  `json:"display"`
+}
+
+// Pattern is a pattern matched in a password. Start and End are rune offsets
+// into the password, End is exclusive, as for Finding.
+type Pattern struct {
+	Pattern string `json:"pattern"`
+	Token   string `json:"token"`
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+
+	// Word is the dictionary word of a dictionary match, Dictionary the list
+	// it is from and Rank its rank there, 1 for the most common
+	Word       string `json:"word,omitempty"`
+	Dictionary string `json:"dictionary,omitempty"`
+	Rank       int    `json:"rank,omitempty"`
+
+	// Detail is the keyboard layout of a walk, the repeated unit of a repeat
+	// or the alphabet of a sequence, and Count the number of turns of a walk
+	// or of units of a repeat
+	Detail string `json:"detail,omitempty"`
+	Count  int    `json:"count,omitempty"`
+
+	// Variant tells how a dictionary word was written: "reversed", "l33t" or
+	// both, empty when as is, and the direction of a sequence
+	Variant string `json:"variant,omitempty"`
+
+	// Date is the date of a date match as YYYY-MM-DD
+	Date string `json:"date,omitempty"`
+
+	// Description is the String of the pattern
+	Description string `json:"description"`
+}
+
+// newPattern returns the Pattern of a match
+func newPattern(match *strengthMatch) Pattern {
+	pattern := Pattern{Pattern: match.pattern, Token: match.token, Start: match.i, End: match.j + 1}
+	switch match.pattern {
+	case "dictionary":
+		pattern.Word, pattern.Dictionary, pattern.Rank = match.matchedWord, match.dictionaryName, match.rank
+		var variants []string
+		if match.reversed {
+			variants = append(variants, "reversed")
+		}
+		if match.l33t {
+			variants = append(variants, "l33t")
+		}
+		pattern.Variant = strings.Join(variants, " ")
+	case "spatial":
+		pattern.Detail, pattern.Count = match.graph, match.turns
+	case "repeat":
+		pattern.Detail, pattern.Count = match.baseToken, match.repeatCount
+	case "sequence":
+		pattern.Detail, pattern.Variant = match.sequenceName, "ascending"
+		if !match.ascending {
+			pattern.Variant = "descending"
+		}
+	case "date":
+		pattern.Date = fmt.Sprintf("%04d-%02d-%02d", match.year, match.month, match.day)
+	}
+	pattern.Description = pattern.String()
+	return pattern
+}
+
+// String returns a short human readable description of the pattern. The rank
+// of a dictionary word and the date of a date are left out when not set.
+func (p Pattern) String() string {
+	switch p.Pattern {
+	case "dictionary":
+		desc := fmt.Sprintf("dictionary word %q (%s", p.Word, p.Dictionary)
+		if p.Rank > 0 {
+			desc += fmt.Sprintf(", rank %d", p.Rank)
+		}
+		if p.Variant != "" {
+			desc += ", " + strings.ReplaceAll(p.Variant, " ", ", ")
+		}
+		return desc + ")"
+	case "spatial":
+		turns := "turns"
+		if p.Count == 1 {
+			turns = "turn"
+		}
+		return fmt.Sprintf("keyboard walk %q (%s, %d %s)", p.Token, p.Detail, p.Count, turns)
+	case "repeat":
+		return fmt.Sprintf("repeat %q (%q x%d)", p.Token, p.Detail, p.Count)
+	case "sequence":
+		return fmt.Sprintf("sequence %q (%s %s)", p.Token, p.Variant, p.Detail)
+	case "regex":
+		return fmt.Sprintf("recent year %q", p.Token)
+	case "date":
+		if p.Date == "" {
+			return fmt.Sprintf("date %q", p.Token)
+		}
+		return fmt.Sprintf("date %q (%s)", p.Token, p.Date)
+	default:
+		return fmt.Sprintf("random characters (%d)", p.End-p.Start)
+	}
+}
+
+// ScoreLabel returns the label of a strength score, e.g. "fair" for 2
+func ScoreLabel(score int) string {
+	if score < 0 || score >= len(scoreLabels) {
+		return ""
+	}
+	return scoreLabels[score]
+}
+
+// EstimateStrength estimates how many guesses an attacker needs for the password
+// and explains the patterns the estimate is based on
+func EstimateStrength(password string) Strength {
+	return newStrength(estimateStrength(password))
+}
+
+func newStrength(result strengthResult) Strength {
+	strength := Strength{
+		Score:       result.score,
+		Label:       scoreLabels[result.score],
+		Guesses:     result.guesses,
+		EntropyBits: result.entropy,
+		CrackTimes:  []CrackTime{},
+		Patterns:    []Pattern{},
+	}
+
+	for _, crackTime := range result.crackTimes {
+		strength.CrackTimes = append(strength.CrackTimes, CrackTime{
+			Scenario: crackTime.scenario,
+			Seconds:  crackTime.seconds,
+			Display:  formatCrackTime(crackTime.seconds),
+		})
+	}
+
+	for _, match := range result.sequence {
+		if match.pattern != "bruteforce" {
+			strength.Patterns = append(strength.Patterns, newPattern(match))
+		}
+	}
+
+	return strength
+}
+
+// estimateStrength estimates how many guesses an attacker needs for the password
+func estimateStrength(password string) strengthResult {
+	runes := []rune(password)
+	matched := runes[:min(len(runes), maxMatchedLength)]
+	result := mostGuessableMatchSequence(matched, omnimatch(matched), false)
+	if len(runes) > len(matched) {
+		result.guesses = capGuesses(result.guesses * bruteforceGuesses(len(runes)-len(matched)))
+		result.sequence = append(result.sequence, bruteforceMatch(runes, len(matched), len(runes)-1))
+	}
+	result.entropy = math.Log2(result.guesses)
+	result.score = guessesToScore(result.guesses)
+
+	for _, scenario := range attackScenarios {
+		result.crackTimes = append(result.crackTimes, crackTime{
+			scenario: scenario.name,
+			seconds:  result.guesses / scenario.rate,
+		})
+	}
+
+	return result
+}
+
+// mostGuessableMatchSequence finds the sequence of non-overlapping matches, filled
+// with bruteforce matches, that needs the fewest guesses to cover the password.
+// A sequence of l matches costs l! * product(guesses) plus a penalty that
+// grows with l.
+func mostGuessableMatchSequence(password []rune, matches []*strengthMatch, excludeAdditive bool) strengthResult {
+	n := len(password)
+	if n == 0 {
+		return strengthResult{guesses: 1}
+	}
+
+	matchesByJ := make([][]*strengthMatch, n)
+	for _, match := range matches {
+		matchesByJ[match.j] = append(matchesByJ[match.j], match)
+	}
+
+	// optimal state per end index, indexed by sequence length. States without
+	// a match are unset, and at most k+1 matches end at index k.
+	type state struct {
+		match *strengthMatch
+		pi    float64
+		g     float64
+	}
+	states := make([]state, n*(n+1))
+	optimal := make([][]state, n)
+	for k := range optimal {
+		optimal[k] = states[k*(n+1) : (k+1)*(n+1)]
+	}
+
+	update := func(match *strengthMatch, l int) {
+		k := match.j
+		pi := estimateGuesses(match, n)
+		if l > 1 {
+			pi = capGuesses(pi * optimal[match.i-1][l-1].pi)
+		}
+		g := factorial(l) * pi
+		if !excludeAdditive {
+			g += math.Pow(minGuessesBeforeGrowingSequence, float64(l-1))
+		}
+		g = capGuesses(g)
+		// Only keep this sequence if no shorter or equal length one is as good
+		for _, competing := range optimal[k][:l+1] {
+			if competing.match != nil && competing.g <= g {
+				return
+			}
+		}
+		optimal[k][l] = state{match: match, pi: pi, g: g}
+	}
+
+	bruteforceUpdate := func(k int) {
+		// One allocation for the bruteforce matches ending at k. Their tokens
+		// are only set for the matches of the chosen sequence.
+		bruteforce := make([]strengthMatch, k+1)
+		for i := range bruteforce {
+			bruteforce[i] = strengthMatch{pattern: "bruteforce", i: i, j: k}
+		}
+		update(&bruteforce[0], 1)
+		for i := 1; i <= k; i++ {
+			match := &bruteforce[i]
+			for l, previous := range optimal[i-1][:i+1] {
+				// Never put two bruteforce matches next to each other
+				if previous.match == nil || previous.match.pattern == "bruteforce" {
+					continue
+				}
+				update(match, l+1)
+			}
+		}
+	}
+
+	for k := 0; k < n; k++ {
+		for _, match := range matchesByJ[k] {
+			if match.i > 0 {
+				for l, previous := range optimal[match.i-1][:match.i+1] {
+					if previous.match != nil {
+						update(match, l+1)
+					}
+				}
+			} else {
+				update(match, 1)
+			}
+		}
+		bruteforceUpdate(k)
+	}
+
+	// Walk back from the end along the best sequence
+	bestL, bestG := 0, math.Inf(1)
+	for l, candidate := range optimal[n-1] {
+		if candidate.match != nil && candidate.g < bestG {
+			bestL, bestG = l, candidate.g
+		}
+	}
+
+	sequence := make([]*strengthMatch, bestL)
+	for k, l := n-1, bestL; k >= 0 && l > 0; l-- {
+		match := optimal[k][l].match
+		if match.pattern == "bruteforce" {
+			match.token = string(password[match.i : match.j+1])
+		}
+		sequence[l-1] = match
+		k = match.i - 1
+	}
+
+	return strengthResult{guesses: bestG, sequence: sequence}
+}
+
+func bruteforceMatch(password []rune, i, j int) *strengthMatch {
+	return &strengthMatch{pattern: "bruteforce", i: i, j: j, token: string(password[i : j+1])}
+}
+
+// estimateGuesses returns (and caches) the guesses needed for a single match
+func estimateGuesses(match *strengthMatch, passwordLength int) float64 {
+	if match.guesses != 0 {
+		return match.guesses
+	}
+
+	tokenLength := match.j - match.i + 1
+	minGuesses := 1.0
+	if tokenLength < passwordLength {
+		minGuesses = minSubmatchGuessesMultiChar
+		if tokenLength == 1 {
+			minGuesses = minSubmatchGuessesSingleChar
+		}
+	}
+
+	var guesses float64
+	switch match.pattern {
+	case "bruteforce":
+		guesses = bruteforceGuesses(tokenLength)
+	case "dictionary":
+		guesses = dictionaryGuesses(match)
+	case "spatial":
+		guesses = spatialGuesses(match)
+	case "repeat":
+		guesses = match.baseGuesses * float64(match.repeatCount)
+	case "sequence":
+		guesses = sequenceGuesses(match)
+	case "regex":
+		guesses = math.Max(math.Abs(float64(atoi(match.token)-referenceYear)), minYearSpace)
+	case "date":
+		guesses = math.Max(math.Abs(float64(match.year-referenceYear)), minYearSpace) * 365
+		if match.separator != "" {
+			guesses *= 4
+		}
+	}
+
+	match.guesses = capGuesses(math.Max(guesses, minGuesses))
+	return match.guesses
+}
+
+// capGuesses limits guesses to maxGuesses
+func capGuesses(guesses float64) float64 {
+	return math.Min(guesses, maxGuesses)
+}
+
+func bruteforceGuesses(length int) float64 {
+	guesses := capGuesses(math.Pow(bruteforceCardinality, float64(length)))
+	if length == 1 {
+		return math.Max(guesses, minSubmatchGuessesSingleChar+1)
+	}
+	return math.Max(guesses, minSubmatchGuessesMultiChar+1)
+}
+
+func dictionaryGuesses(match *strengthMatch) float64 {
+	guesses := float64(match.rank) * uppercaseVariations(match.token) * l33tVariations(match)
+	if match.reversed {
+		guesses *= 2
+	}
+	return guesses
+}
+
+// uppercaseVariations counts the ways the word could have been capitalised
+func uppercaseVariations(word string) float64 {
+	upper, lower := 0, 0
+	for _, r := range word {
+		if unicode.IsUpper(r) {
+			upper++
+		} else if unicode.IsLower(r) {
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 1
+	}
+
+	// First letter, last letter or everything capitalised are common and cheap
+	runes := []rune(word)
+	if lower == 0 ||
+		(upper == 1 && unicode.IsUpper(runes[0])) ||
+		(upper == 1 && unicode.IsUpper(runes[len(runes)-1])) {
+		return 2
+	}
+
+	variations := 0.0
+	for i := 1; i <= min(upper, lower); i++ {
+		variations += nCk(upper+lower, i)
+	}
+	return variations
+}
+
+// l33tVariations counts the ways the substitutions could have been applied
+func l33tVariations(match *strengthMatch) float64 {
+	if !match.l33t {
+		return 1
+	}
+
+	variations := 1.0
+	lower := strings.ToLower(match.token)
+	for subbed, unsubbed := range match.sub {
+		s := strings.Count(lower, string(subbed))
+		u := strings.Count(lower, string(unsubbed))
+		if s == 0 || u == 0 {
+			variations *= 2
+			continue
+		}
+		possibilities := 0.0
+		for i := 1; i <= min(s, u); i++ {
+			possibilities += nCk(s+u, i)
+		}
+		variations *= possibilities
+	}
+	return variations
+}
+
+func spatialGuesses(match *strengthMatch) float64 {
+	var graph keyboardGraph
+	for _, g := range keyboardGraphs() {
+		if g.name == match.graph {
+			graph = g
+		}
+	}
+
+	length := len([]rune(match.token))
+	guesses := 0.0
+	for i := 2; i <= length; i++ {
+		for j := 1; j <= min(match.turns, i-1); j++ {
+			guesses += nCk(i-1, j-1) * graph.startingCount * math.Pow(graph.averageDegree, float64(j))
+		}
+	}
+
+	// Add the ways the shift key could have been used
+	if match.shiftedCount > 0 {
+		s, u := match.shiftedCount, length-match.shiftedCount
+		if s == 0 || u == 0 {
+			guesses *= 2
+		} else {
+			variations := 0.0
+			for i := 1; i <= min(s, u); i++ {
+				variations += nCk(s+u, i)
+			}
+			guesses *= variations
+		}
+	}
+	return guesses
+}
+
+func sequenceGuesses(match *strengthMatch) float64 {
+	first := []rune(match.token)[0]
+	var base float64
+	switch {
+	case strings.ContainsRune("aAzZ019", first):
+		// Obvious starting points
+		base = 4
+	case first >= '0' && first <= '9':
+		base = 10
+	default:
+		base = 26
+	}
+	if !match.ascending {
+		base *= 2
+	}
+	return base * float64(len([]rune(match.token)))
+}
+
+// guessesToScore maps guesses to a 0-4 score
+func guessesToScore(guesses float64) int {
+	const delta = 5
+	switch {
+	case guesses < 1e3+delta:
+		return 0
+	case guesses < 1e6+delta:
+		return 1
+	case guesses < 1e8+delta:
+		return 2
+	case guesses < 1e10+delta:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// formatCrackTime turns seconds into a human readable duration
+func formatCrackTime(seconds float64) string {
+	const (
+		minute  = 60
+		hour    = minute * 60
+		day     = hour * 24
+		month   = day * 31
+		year    = month * 12
+		century = year * 100
+	)
+
+	units := []struct {
+		size float64
+		name string
+	}{
+		{year, "year"},
+		{month, "month"},
+		{day, "day"},
+		{hour, "hour"},
+		{minute, "minute"},
+		{1, "second"},
+	}
+
+	if seconds < 1 {
+		return "less than a second"
+	}
+	if seconds >= century {
+		return "centuries"
+	}
+	for _, unit := range units {
+		if seconds >= unit.size {
+			n := math.Round(seconds / unit.size)
+			if n == 1 {
+				return fmt.Sprintf("1 %s", unit.name)
+			}
+			return fmt.Sprintf("%.0f %ss", n, unit.name)
+		}
+	}
+	return "less than a second"
+}
+
+func factorial(n int) float64 {
+	f := 1.0
+	for i := 2; i <= n; i++ {
+		f *= float64(i)
+	}
+	return f
+}
+
+// nCk returns the binomial coefficient n choose k
+func nCk(n, k int) float64 {
+	if k > n {
+		return 0
+	}
+	if k == 0 {
+		return 1
+	}
+	r := 1.0
+	for d := 1; d <= k; d++ {
+		r *= float64(n)
+		r /= float64(d)
+		n--
+	}
+	return r
+}
@@ -0,0 +1,712 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package analyzer
+
+import (
+	_ "embed"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Frequency ranked lists from the zxcvbn project (MIT licensed), most common first
+var (
+	//go:embed wordlists/passwords.txt
+	passwordsWordlist string
+	//go:embed wordlists/english.txt
+	englishWordlist string
+	//go:embed wordlists/female_names.txt
+	femaleNamesWordlist string
+	//go:embed wordlists/male_names.txt
+	maleNamesWordlist string
+	//go:embed wordlists/surnames.txt
+	surnamesWordlist string
+)
+
+// rankedDictionary maps lowercase words to their frequency rank (1 is most common)
+type rankedDictionary struct {
+	name  string
+	ranks map[string]int
+}
+
+// rankedDictionaries returns the dictionaries searched for dictionary words
+var rankedDictionaries = sync.OnceValue(func() []rankedDictionary {
+	return []rankedDictionary{
+		newRankedDictionary("passwords", passwordsWordlist),
+		newRankedDictionary("english", englishWordlist),
+		newRankedDictionary("female names", femaleNamesWordlist),
+		newRankedDictionary("male names", maleNamesWordlist),
+		newRankedDictionary("surnames", surnamesWordlist),
+	}
+})
+
+func newRankedDictionary(name, data string) rankedDictionary {
+	ranks := make(map[string]int)
+	rank := 0
+	for _, line := range strings.Split(data, "\n") {
+		word := strings.TrimSpace(line)
+		if word == "" {
+			continue
+		}
+		// Duplicates keep the rank of their first occurrence
+		if _, seen := ranks[strings.ToLower(word)]; !seen {
+			rank++
+			ranks[strings.ToLower(word)] = rank
+		}
+	}
+	return rankedDictionary{name: name, ranks: ranks}
+}
+
+// dictionaryWord holds the ranks of a word in each of the ranked
+// dictionaries, 0 where it is missing
+type dictionaryWord struct {
+	ranks []int
+}
+
+// dictionaryIndex returns the words of all ranked dictionaries, so that
+// dictionaryMatch looks up each substring once. The prefixes of the words are
+// in the index too, without ranks, so that it stops extending a substring
+// once no word starts with it.
+var dictionaryIndex = sync.OnceValue(func() map[string]dictionaryWord {
+	dictionaries := rankedDictionaries()
+	index := make(map[string]dictionaryWord)
+	for d, dictionary := range dictionaries {
+		for word, rank := range dictionary.ranks {
+			for end := range word {
+				if _, ok := index[word[:end]]; end > 0 && !ok {
+					index[word[:end]] = dictionaryWord{}
+				}
+			}
+			entry := index[word]
+			if entry.ranks == nil {
+				entry.ranks = make([]int, len(dictionaries))
+			}
+			entry.ranks[d] = rank
+			index[word] = entry
+		}
+	}
+	return index
+})
+
+// l33tTable lists the common substitutions for each letter
+var l33tTable = map[rune][]rune{
+	'a': {'4', '@'},
+	'b': {'8'},
+	'c': {'(', '{', '[', '<'},
+	'e': {'3'},
+	'g': {'6', '9'},
+	'i': {'1', '!', '|'},
+	'l': {'1', '|', '7'},
+	'o': {'0'},
+	's': {'$', '5'},
+	't': {'+', '7'},
+	'x': {'%'},
+	'z': {'2'},
+}
+
+// strengthMatch is a pattern found in a password. Spans are inclusive rune indexes.
+type strengthMatch struct {
+	pattern string
+	i, j    int
+	token   string
+	guesses float64
+
+	// dictionary
+	dictionaryName string
+	matchedWord    string
+	rank           int
+	reversed       bool
+	l33t           bool
+	sub            map[rune]rune
+
+	// spatial
+	graph        string
+	turns        int
+	shiftedCount int
+
+	// repeat
+	baseToken   string
+	baseGuesses float64
+	repeatCount int
+
+	// sequence
+	sequenceName  string
+	sequenceSpace int
+	ascending     bool
+
+	// regex
+	regexName string
+
+	// date
+	separator        string
+	year, month, day int
+}
+
+// omnimatch runs every matcher over the password and returns the matches sorted by span
+func omnimatch(password []rune) []*strengthMatch {
+	var matches []*strengthMatch
+	matches = append(matches, dictionaryMatch(password)...)
+	matches = append(matches, reverseDictionaryMatch(password)...)
+	matches = append(matches, l33tMatch(password)...)
+	matches = append(matches, spatialMatch(password)...)
+	matches = append(matches, repeatMatch(password)...)
+	matches = append(matches, sequenceMatch(password)...)
+	matches = append(matches, regexMatch(password)...)
+	matches = append(matches, dateMatch(password)...)
+	sortMatches(matches)
+	return matches
+}
+
+func sortMatches(matches []*strengthMatch) {
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].i != matches[b].i {
+			return matches[a].i < matches[b].i
+		}
+		return matches[a].j < matches[b].j
+	})
+}
+
+// dictionaryMatch finds every substring that is a word in one of the ranked
+// dictionaries. It runs for every password analyzed, so candidate words are
+// substrings of the lowercased password rather than new strings.
+func dictionaryMatch(password []rune) []*strengthMatch {
+	var matches []*strengthMatch
+	lower := strings.ToLower(string(password))
+
+	// Byte offsets of the runes of lower
+	offsets := make([]int, 0, len(password)+1)
+	for offset := range lower {
+		offsets = append(offsets, offset)
+	}
+	if len(offsets) != len(password) {
+		return nil
+	}
+	offsets = append(offsets, len(lower))
+
+	dictionaries, index := rankedDictionaries(), dictionaryIndex()
+	for i := range password {
+		for j := i; j < len(password); j++ {
+			word := lower[offsets[i]:offsets[j+1]]
+			entry, ok := index[word]
+			if !ok {
+				break
+			}
+			for d, rank := range entry.ranks {
+				if rank > 0 {
+					matches = append(matches, &strengthMatch{
+						pattern:        "dictionary",
+						i:              i,
+						j:              j,
+						token:          string(password[i : j+1]),
+						dictionaryName: dictionaries[d].name,
+						matchedWord:    word,
+						rank:           rank,
+					})
+				}
+			}
+		}
+	}
+
+	sortMatches(matches)
+	return matches
+}
+
+// reverseDictionaryMatch finds dictionary words written backwards
+func reverseDictionaryMatch(password []rune) []*strengthMatch {
+	reversed := reverseRunes(password)
+	matches := dictionaryMatch(reversed)
+	for _, match := range matches {
+		match.token = string(reverseRunes([]rune(match.token)))
+		match.reversed = true
+		match.i, match.j = len(password)-1-match.j, len(password)-1-match.i
+	}
+
+	sortMatches(matches)
+	return matches
+}
+
+// l33tMatch finds dictionary words written with l33t substitutions such as p4ssw0rd
+func l33tMatch(password []rune) []*strengthMatch {
+	var matches []*strengthMatch
+
+	for _, sub := range enumerateL33tSubs(password) {
+		subbed := make([]rune, len(password))
+		for i, char := range password {
+			if letter, ok := sub[char]; ok {
+				char = letter
+			}
+			subbed[i] = char
+		}
+
+		for _, match := range dictionaryMatch(subbed) {
+			token := password[match.i : match.j+1]
+			// Only keep matches that contain an actual substitution
+			if strings.ToLower(string(token)) == match.matchedWord || len(token) < 2 {
+				continue
+			}
+
+			matchSub := make(map[rune]rune)
+			for l33tChar, letter := range sub {
+				if containsRune(token, l33tChar) {
+					matchSub[l33tChar] = letter
+				}
+			}
+
+			match.token = string(token)
+			match.l33t = true
+			match.sub = matchSub
+			matches = append(matches, match)
+		}
+	}
+
+	sortMatches(matches)
+	return dedupeMatches(matches)
+}
+
+// enumerateL33tSubs returns every way the l33t characters in the password can be
+// mapped back to letters. Characters such as '1' can stand for more than one letter.
+func enumerateL33tSubs(password []rune) []map[rune]rune {
+	options := make(map[rune][]rune)
+	for letter, subs := range l33tTable {
+		for _, sub := range subs {
+			if containsRune(password, sub) {
+				options[sub] = append(options[sub], letter)
+			}
+		}
+	}
+	if len(options) == 0 {
+		return nil
+	}
+
+	var chars []rune
+	for char := range options {
+		chars = append(chars, char)
+		sort.Slice(options[char], func(a, b int) bool { return options[char][a] < options[char][b] })
+	}
+	sort.Slice(chars, func(a, b int) bool { return chars[a] < chars[b] })
+
+	subs := []map[rune]rune{{}}
+	for _, char := range chars {
+		var next []map[rune]rune
+		for _, sub := range subs {
+			for _, letter := range options[char] {
+				extended := make(map[rune]rune, len(sub)+1)
+				for k, v := range sub {
+					extended[k] = v
+				}
+				extended[char] = letter
+				next = append(next, extended)
+			}
+		}
+		subs = next
+	}
+	return subs
+}
+
+// dedupeMatches drops matches with the same span and word, keeping the first
+func dedupeMatches(matches []*strengthMatch) []*strengthMatch {
+	seen := make(map[string]bool)
+	var unique []*strengthMatch
+	for _, match := range matches {
+		key := strconv.Itoa(match.i) + ":" + strconv.Itoa(match.j) + ":" + match.dictionaryName + ":" + match.matchedWord
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, match)
+		}
+	}
+	return unique
+}
+
+// spatialMatch finds keyboard walks such as qwerty or 7412 on every known layout
+func spatialMatch(password []rune) []*strengthMatch {
+	var matches []*strengthMatch
+	for _, graph := range keyboardGraphs() {
+		matches = append(matches, spatialMatchGraph(password, graph)...)
+	}
+	sortMatches(matches)
+	return matches
+}
+
+func spatialMatchGraph(password []rune, graph keyboardGraph) []*strengthMatch {
+	var matches []*strengthMatch
+
+	i := 0
+	for i < len(password)-1 {
+		j := i + 1
+		lastDirection := -1
+		turns := 0
+		shiftedCount := 0
+		if graph.shifted[password[i]] {
+			shiftedCount = 1
+		}
+
+		for {
+			found := false
+			if j < len(password) {
+				for direction, neighbour := range graph.graph[password[j-1]] {
+					index := strings.IndexRune(neighbour, password[j])
+					if neighbour == "" || index < 0 {
+						continue
+					}
+					found = true
+					// The shifted character is always the second one of a key
+					if index > 0 {
+						shiftedCount++
+					}
+					if lastDirection != direction {
+						turns++
+						lastDirection = direction
+					}
+					break
+				}
+			}
+
+			if found {
+				j++
+				continue
+			}
+
+			// Walks of three or more keys are reported
+			if j-i > 2 {
+				matches = append(matches, &strengthMatch{
+					pattern:      "spatial",
+					i:            i,
+					j:            j - 1,
+					token:        string(password[i:j]),
+					graph:        graph.name,
+					turns:        turns,
+					shiftedCount: shiftedCount,
+				})
+			}
+			i = j
+			break
+		}
+	}
+
+	return matches
+}
+
+// repeatMatch finds repeated substrings such as aaaa or abcabc
+func repeatMatch(password []rune) []*strengthMatch {
+	var matches []*strengthMatch
+
+	i := 0
+	for i < len(password) {
+		bestUnit, bestCount := longestRepetition(password[i:])
+		if bestCount < 2 {
+			i++
+			continue
+		}
+
+		j := i + bestUnit*bestCount - 1
+		base := password[i : i+bestUnit]
+		baseResult := mostGuessableMatchSequence(base, omnimatch(base), false)
+		matches = append(matches, &strengthMatch{
+			pattern:     "repeat",
+			i:           i,
+			j:           j,
+			token:       string(password[i : j+1]),
+			baseToken:   string(base),
+			baseGuesses: baseResult.guesses,
+			repeatCount: bestCount,
+		})
+		i = j + 1
+	}
+
+	return matches
+}
+
+// maxSequenceDelta is the largest step between characters still treated as a sequence
+const maxSequenceDelta = 5
+
+// sequenceMatch finds runs with a constant step such as abcd, 9876 or 1357
+func sequenceMatch(password []rune) []*strengthMatch {
+	var matches []*strengthMatch
+	if len(password) < 2 {
+		return nil
+	}
+
+	update := func(i, j, delta int) {
+		absDelta := delta
+		if absDelta < 0 {
+			absDelta = -absDelta
+		}
+		if (j-i > 1 || absDelta == 1) && absDelta > 0 && absDelta <= maxSequenceDelta {
+			token := string(password[i : j+1])
+			name, space := "unicode", 26
+			switch {
+			case isAllRunes(token, func(r rune) bool { return r >= 'a' && r <= 'z' }):
+				name, space = "lower", 26
+			case isAllRunes(token, func(r rune) bool { return r >= 'A' && r <= 'Z' }):
+				name, space = "upper", 26
+			case isAllRunes(token, func(r rune) bool { return r >= '0' && r <= '9' }):
+				name, space = "digits", 10
+			}
+			matches = append(matches, &strengthMatch{
+				pattern:       "sequence",
+				i:             i,
+				j:             j,
+				token:         token,
+				sequenceName:  name,
+				sequenceSpace: space,
+				ascending:     delta > 0,
+			})
+		}
+	}
+
+	i := 0
+	lastDelta := int(password[1] - password[0])
+	for k := 1; k < len(password); k++ {
+		delta := int(password[k] - password[k-1])
+		if delta == lastDelta {
+			continue
+		}
+		j := k - 1
+		update(i, j, lastDelta)
+		i = j
+		lastDelta = delta
+	}
+	update(i, len(password)-1, lastDelta)
+
+	return matches
+}
+
+var recentYearPattern = regexp.MustCompile(`19\d\d|20\d\d`)
+
+// regexMatch finds recent years
+func regexMatch(password []rune) []*strengthMatch {
+	var matches []*strengthMatch
+	for _, span := range findRuneSpans(recentYearPattern, password) {
+		matches = append(matches, &strengthMatch{
+			pattern:   "regex",
+			i:         span[0],
+			j:         span[1],
+			token:     string(password[span[0] : span[1]+1]),
+			regexName: "recent_year",
+		})
+	}
+	return matches
+}
+
+// Date detection limits
+const (
+	dateMinYear = 1000
+	dateMaxYear = 2050
+)
+
+// dateSplits lists where a run of digits of the given length may be split into
+// day, month and year
+var dateSplits = map[int][][2]int{
+	4: {{1, 2}, {2, 3}},
+	5: {{1, 3}, {2, 3}},
+	6: {{1, 2}, {2, 4}, {4, 5}},
+	7: {{1, 3}, {2, 3}, {4, 5}, {4, 6}},
+	8: {{2, 4}, {4, 6}},
+}
+
+var (
+	digitsOnlyPattern    = regexp.MustCompile(`^\d{4,8}$`)
+	dateSeparatorPattern = regexp.MustCompile(`^(\d{1,4})([\s/\\_.-])(\d{1,2})([\s/\\_.-])(\d{1,4})$`)
+)
+
+// dateMatch finds dates with or without separators, e.g. 13/05/1990 or 130590
+func dateMatch(password []rune) []*strengthMatch {
+	var matches []*strengthMatch
+
+	// Dates without separators
+	for i := 0; i+4 <= len(password); i++ {
+		// The digits starting at i bound the candidates, which saves building
+		// and matching tokens at every position of long passwords
+		digits := 0
+		for i+digits < len(password) && isASCIIDigit(password[i+digits]) {
+			digits++
+		}
+		for j := i + 3; j <= i+7 && j < i+digits; j++ {
+			token := string(password[i : j+1])
+			if !digitsOnlyPattern.MatchString(token) {
+				continue
+			}
+
+			var best *strengthMatch
+			for _, split := range dateSplits[len(token)] {
+				ints := [3]int{atoi(token[:split[0]]), atoi(token[split[0]:split[1]]), atoi(token[split[1]:])}
+				year, month, day, ok := mapIntsToDMY(ints)
+				if !ok {
+					continue
+				}
+				if best == nil || absInt(year-referenceYear) < absInt(best.year-referenceYear) {
+					best = &strengthMatch{pattern: "date", i: i, j: j, token: token, year: year, month: month, day: day}
+				}
+			}
+			if best != nil {
+				matches = append(matches, best)
+			}
+		}
+	}
+
+	// Dates with separators
+	for i := 0; i+6 <= len(password); i++ {
+		if !isASCIIDigit(password[i]) {
+			continue
+		}
+		for j := i + 5; j <= i+9 && j < len(password); j++ {
+			if !isASCIIDigit(password[j]) {
+				continue
+			}
+			token := string(password[i : j+1])
+			parts := dateSeparatorPattern.FindStringSubmatch(token)
+			if parts == nil || parts[2] != parts[4] {
+				continue
+			}
+			year, month, day, ok := mapIntsToDMY([3]int{atoi(parts[1]), atoi(parts[3]), atoi(parts[5])})
+			if !ok {
+				continue
+			}
+			matches = append(matches, &strengthMatch{pattern: "date", i: i, j: j, token: token, separator: parts[2], year: year, month: month, day: day})
+		}
+	}
+
+	// Drop dates that are contained in other dates
+	var filtered []*strengthMatch
+	for _, match := range matches {
+		contained := false
+		for _, other := range matches {
+			if match != other && other.i <= match.i && other.j >= match.j {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			filtered = append(filtered, match)
+		}
+	}
+
+	sortMatches(filtered)
+	return filtered
+}
+
+// mapIntsToDMY interprets three integers as a day, month and year in any common order
+func mapIntsToDMY(ints [3]int) (year, month, day int, ok bool) {
+	if ints[1] > 31 || ints[1] <= 0 {
+		return 0, 0, 0, false
+	}
+
+	over12, over31, under1 := 0, 0, 0
+	for _, n := range ints {
+		if (n > 99 && n < dateMinYear) || n > dateMaxYear {
+			return 0, 0, 0, false
+		}
+		if n > 31 {
+			over31++
+		}
+		if n > 12 {
+			over12++
+		}
+		if n <= 0 {
+			under1++
+		}
+	}
+	if over31 >= 2 || over12 == 3 || under1 >= 2 {
+		return 0, 0, 0, false
+	}
+
+	splits := []struct {
+		year int
+		rest [2]int
+	}{
+		{ints[2], [2]int{ints[0], ints[1]}}, // year last
+		{ints[0], [2]int{ints[1], ints[2]}}, // year first
+	}
+
+	for _, split := range splits {
+		if split.year >= dateMinYear && split.year <= dateMaxYear {
+			month, day, ok := mapIntsToDM(split.rest)
+			return split.year, month, day, ok
+		}
+	}
+
+	// Two digit years
+	for _, split := range splits {
+		if month, day, ok := mapIntsToDM(split.rest); ok {
+			year := split.year
+			if year <= 99 {
+				if year > 50 {
+					year += 1900
+				} else {
+					year += 2000
+				}
+			}
+			return year, month, day, true
+		}
+	}
+
+	return 0, 0, 0, false
+}
+
+func mapIntsToDM(ints [2]int) (month, day int, ok bool) {
+	for _, dm := range [][2]int{{ints[0], ints[1]}, {ints[1], ints[0]}} {
+		d, m := dm[0], dm[1]
+		if d >= 1 && d <= 31 && m >= 1 && m <= 12 {
+			return m, d, true
+		}
+	}
+	return 0, 0, false
+}
+
+// findRuneSpans returns the inclusive rune spans of all matches of pattern
+func findRuneSpans(pattern *regexp.Regexp, password []rune) [][2]int {
+	s := string(password)
+	var spans [][2]int
+	for _, loc := range pattern.FindAllStringIndex(s, -1) {
+		start := len([]rune(s[:loc[0]]))
+		end := start + len([]rune(s[loc[0]:loc[1]])) - 1
+		spans = append(spans, [2]int{start, end})
+	}
+	return spans
+}
+
+func reverseRunes(runes []rune) []rune {
+	reversed := make([]rune, len(runes))
+	for i, r := range runes {
+		reversed[len(runes)-1-i] = r
+	}
+	return reversed
+}
+
+func containsRune(runes []rune, r rune) bool {
+	for _, c := range runes {
+		if c == r {
+			return true
+		}
+	}
+	return false
+}
+
+func isAllRunes(s string, f func(rune) bool) bool {
+	for _, r := range s {
+		if !f(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// isASCIIDigit reports whether char is one of 0-9, the digits of \d
+func isASCIIDigit(char rune) bool {
+	return '0' <= char && char <= '9'
+}
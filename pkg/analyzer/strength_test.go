@@ -0,0 +1,175 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEstimateStrengthPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		pattern  string
+		maxScore int
+	}{
+		{"Common password", "password", "dictionary", 0},
+		{"Capitalized password with digit", "Password1", "dictionary", 0},
+		{"L33t substitution", "p4ssw0rd", "dictionary", 0},
+		{"Reversed word", "drowssap", "dictionary", 0},
+		{"Keyboard walk", "zxcvfdsa", "spatial", 1},
+		{"Keypad walk", "7412369", "spatial", 1},
+		{"Repeated characters", "aaaaaaaa", "repeat", 0},
+		{"Repeated substring", "xyzxyzxyz", "repeat", 1},
+		{"Ascending sequence", "abcdefgh", "sequence", 0},
+		{"Descending sequence", "98765", "sequence", 0},
+		{"Recent year", "1987", "regex", 0},
+		{"Date with separators", "13/05/1990", "date", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := estimateStrength(tt.password)
+
+			found := false
+			for _, match := range result.sequence {
+				if match.pattern == tt.pattern {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Expected %s pattern in %q, got %v", tt.pattern, tt.password, describeSequence(result.sequence))
+			}
+
+			if result.score > tt.maxScore {
+				t.Errorf("Score for %q = %d, want at most %d", tt.password, result.score, tt.maxScore)
+			}
+		})
+	}
+}
+
+func TestEstimateStrengthRandom(t *testing.T) {
+	result := estimateStrength("kX9#vQ2mLp7z")
+
+	if result.score != 4 {
+		t.Errorf("Expected score 4 for a random password, got %d", result.score)
+	}
+	if result.entropy < 35 {
+		t.Errorf("Expected at least 35 bits of entropy, got %.1f", result.entropy)
+	}
+	if len(result.crackTimes) != len(attackScenarios) {
+		t.Errorf("Expected %d crack times, got %d", len(attackScenarios), len(result.crackTimes))
+	}
+}
+
+func TestEstimateStrengthEmpty(t *testing.T) {
+	result := estimateStrength("")
+
+	if result.guesses != 1 || result.score != 0 {
+		t.Errorf("Expected 1 guess and score 0 for empty password, got %v guesses and score %d", result.guesses, result.score)
+	}
+}
+
+func TestEstimateStrengthSpans(t *testing.T) {
+	patterns := EstimateStrength("password").Patterns
+	if len(patterns) != 1 || patterns[0].Start != 0 || patterns[0].End != 8 {
+		t.Errorf("Expected one pattern spanning 0-8, got %+v", patterns)
+	}
+}
+
+func TestEstimateStrengthLong(t *testing.T) {
+	password := strings.Repeat("correcthorse1!", 72)[:1000]
+	strength := EstimateStrength(password)
+
+	if math.IsInf(strength.Guesses, 0) || math.IsInf(strength.EntropyBits, 0) || strength.Score != 4 {
+		t.Errorf("Unexpected strength %v guesses, %v bits, score %d", strength.Guesses, strength.EntropyBits, strength.Score)
+	}
+	for _, crackTime := range strength.CrackTimes {
+		if math.IsInf(crackTime.Seconds, 0) {
+			t.Errorf("Infinite crack time for %s", crackTime.Scenario)
+		}
+	}
+	if _, err := json.Marshal(strength); err != nil {
+		t.Errorf("Cannot encode the strength: %v", err)
+	}
+}
+
+func TestGuessesToScore(t *testing.T) {
+	tests := []struct {
+		guesses float64
+		want    int
+	}{
+		{1, 0},
+		{1e3, 0},
+		{1e5, 1},
+		{1e7, 2},
+		{1e9, 3},
+		{1e12, 4},
+	}
+
+	for _, tt := range tests {
+		if got := guessesToScore(tt.guesses); got != tt.want {
+			t.Errorf("guessesToScore(%g) = %d, want %d", tt.guesses, got, tt.want)
+		}
+	}
+}
+
+func TestFormatCrackTime(t *testing.T) {
+	tests := []struct {
+		seconds float64
+		want    string
+	}{
+		{0.5, "less than a second"},
+		{1, "1 second"},
+		{90, "2 minutes"},
+		{3 * 3600, "3 hours"},
+		{86400 * 31 * 12 * 5, "5 years"},
+		{1e12, "centuries"},
+	}
+
+	for _, tt := range tests {
+		if got := formatCrackTime(tt.seconds); got != tt.want {
+			t.Errorf("formatCrackTime(%g) = %q, want %q", tt.seconds, got, tt.want)
+		}
+	}
+}
+
+func TestKeyboardGraphs(t *testing.T) {
+	for _, graph := range keyboardGraphs() {
+		if graph.name == "qwerty" {
+			neighbours := graph.graph['g']
+			want := []string{"fF", "tT", "yY", "hH", "bB", "vV"}
+			for i, n := range want {
+				if neighbours[i] != n {
+					t.Errorf("qwerty neighbours of 'g' = %v, want %v", neighbours, want)
+					break
+				}
+			}
+			if !graph.shifted['G'] || graph.shifted['g'] {
+				t.Errorf("Expected 'G' to be shifted and 'g' not to be")
+			}
+		}
+		if graph.name == "azerty" {
+			neighbours := graph.graph['w']
+			want := []string{"<>", "qQ", "sS", "xX", "", ""}
+			for i, n := range want {
+				if neighbours[i] != n {
+					t.Errorf("azerty neighbours of 'w' = %v, want %v", neighbours, want)
+					break
+				}
+			}
+		}
+		if graph.averageDegree <= 0 || graph.startingCount <= 0 {
+			t.Errorf("Graph %s has no keys", graph.name)
+		}
+	}
+}
+
+func describeSequence(sequence []*strengthMatch) []string {
+	var descriptions []string
+	for _, match := range sequence {
+		descriptions = append(descriptions, newPattern(match).String())
+	}
+	return descriptions
+}
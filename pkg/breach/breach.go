@@ -0,0 +1,262 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+
+// Package breach looks up passwords in local, offline copies of breached
+// password corpora such as Have I Been Pwned's Pwned Passwords.
+//
+//	db, err := breach.Open("pwned.pzdb")
+//	if err != nil {
+//		return err
+//	}
+//	defer db.Close()
+//	count, err := db.Count("password")
+package breach
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Breach corpora are Pwned Passwords style SHA-1 lists: one "HASH:COUNT" line
+// per password, sorted by hash. They are searched in place with a binary search,
+// or through a compact index written by "breachdb build":
+//
+//	header  magic "PZBREACH" | version uint32 | record count uint64
+//	records SHA-1 [20]byte | count uint32   (sorted by hash)
+//
+// All integers are big endian.
+const (
+	indexMagic      = "PZBREACH"
+	indexVersion    = 1
+	indexHeaderSize = 8 + 4 + 8
+	indexRecordSize = sha1.Size + 4
+)
+
+// DB looks up passwords in a local breach corpus
+type DB struct {
+	file    *os.File
+	size    int64
+	indexed bool
+	records int64
+}
+
+// Open opens a breach corpus, either a sorted text file or an index written by BuildIndex
+func Open(path string) (*DB, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open breach database: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("cannot access breach database: %v", err)
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, fmt.Errorf("path is a directory, not a file: %s", path)
+	}
+
+	db := &DB{file: file, size: info.Size()}
+
+	header := make([]byte, indexHeaderSize)
+	if _, err := file.ReadAt(header, 0); err == nil && string(header[:8]) == indexMagic {
+		if version := binary.BigEndian.Uint32(header[8:12]); version != indexVersion {
+			file.Close()
+			return nil, fmt.Errorf("unsupported breach index version %d", version)
+		}
+		db.indexed = true
+		db.records = int64(binary.BigEndian.Uint64(header[12:20]))
+		if indexHeaderSize+db.records*indexRecordSize != db.size {
+			file.Close()
+			return nil, fmt.Errorf("breach index is truncated or corrupt")
+		}
+	}
+
+	return db, nil
+}
+
+// Close closes the underlying file
+func (db *DB) Close() error {
+	return db.file.Close()
+}
+
+// Count returns how many times the password appears in the corpus, 0 when it does not
+func (db *DB) Count(password string) (int, error) {
+	hash := sha1.Sum([]byte(password))
+	if db.indexed {
+		return db.lookupIndex(hash)
+	}
+	return db.lookupText(strings.ToUpper(hex.EncodeToString(hash[:])))
+}
+
+// lookupIndex binary searches the fixed size records of a built index
+func (db *DB) lookupIndex(hash [sha1.Size]byte) (int, error) {
+	record := make([]byte, indexRecordSize)
+	lo, hi := int64(0), db.records
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if _, err := db.file.ReadAt(record, indexHeaderSize+mid*indexRecordSize); err != nil {
+			return 0, fmt.Errorf("error reading breach index: %v", err)
+		}
+		switch cmp := bytes.Compare(record[:sha1.Size], hash[:]); {
+		case cmp == 0:
+			return int(binary.BigEndian.Uint32(record[sha1.Size:])), nil
+		case cmp < 0:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+	return 0, nil
+}
+
+// lookupText binary searches a sorted "HASH:COUNT" text file by byte offset.
+// The search keeps the invariant that the line of the hash, if present, starts
+// within [lo, hi).
+func (db *DB) lookupText(hash string) (int, error) {
+	lo, hi := int64(0), db.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		start, line, err := db.lineAt(mid)
+		if err != nil {
+			return 0, err
+		}
+		if start < 0 {
+			hi = mid
+			continue
+		}
+
+		lineHash, count, err := parseLine(line)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case lineHash == hash:
+			return count, nil
+		case lineHash < hash:
+			lo = start + int64(len(line)) + 1
+		default:
+			hi = mid
+		}
+	}
+	return 0, nil
+}
+
+// lineAt returns the first line starting at or after offset, or a negative start
+// when there is none
+func (db *DB) lineAt(offset int64) (int64, string, error) {
+	start := offset
+	if offset > 0 {
+		// Skip the rest of the line offset falls into
+		reader := bufio.NewReader(io.NewSectionReader(db.file, offset-1, db.size-offset+1))
+		skipped, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return -1, "", nil
+		} else if err != nil {
+			return 0, "", fmt.Errorf("error reading breach database: %v", err)
+		}
+		start = offset - 1 + int64(len(skipped))
+	}
+	if start >= db.size {
+		return -1, "", nil
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(db.file, start, db.size-start))
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return 0, "", fmt.Errorf("error reading breach database: %v", err)
+	}
+	return start, strings.TrimSuffix(line, "\n"), nil
+}
+
+// parseLine splits a "HASH:COUNT" line into the uppercase hash and its count
+func parseLine(line string) (string, int, error) {
+	hash, count, found := strings.Cut(strings.TrimSpace(line), ":")
+	if !found || len(hash) != 2*sha1.Size {
+		return "", 0, fmt.Errorf("invalid breach database line: %q", line)
+	}
+	n, err := strconv.ParseInt(count, 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid breach count in line: %q", line)
+	}
+	return strings.ToUpper(hash), int(min(n, math.MaxInt32)), nil
+}
+
+// BuildIndex compacts a sorted "HASH:COUNT" text corpus into an index.
+// It returns the number of hashes written.
+func BuildIndex(input io.Reader, output io.WriterAt) (int64, error) {
+	writer := &offsetWriter{w: output, offset: indexHeaderSize}
+	buffered := bufio.NewWriterSize(writer, 1<<20)
+
+	scanner := bufio.NewScanner(input)
+	var records int64
+	var previous []byte
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		hash, count, err := parseLine(line)
+		if err != nil {
+			return 0, fmt.Errorf("line %d: %v", lineNum, err)
+		}
+		raw, err := hex.DecodeString(hash)
+		if err != nil {
+			return 0, fmt.Errorf("line %d: invalid hash: %v", lineNum, err)
+		}
+		if previous != nil && bytes.Compare(previous, raw) >= 0 {
+			return 0, fmt.Errorf("line %d: hashes must be sorted and unique", lineNum)
+		}
+		previous = raw
+
+		record := make([]byte, indexRecordSize)
+		copy(record, raw)
+		binary.BigEndian.PutUint32(record[sha1.Size:], uint32(count))
+		if _, err := buffered.Write(record); err != nil {
+			return 0, fmt.Errorf("error writing breach index: %v", err)
+		}
+		records++
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("error reading breach corpus: %v", err)
+	}
+	if err := buffered.Flush(); err != nil {
+		return 0, fmt.Errorf("error writing breach index: %v", err)
+	}
+
+	// The header goes last, once the record count is known
+	header := make([]byte, indexHeaderSize)
+	copy(header, indexMagic)
+	binary.BigEndian.PutUint32(header[8:12], indexVersion)
+	binary.BigEndian.PutUint64(header[12:20], uint64(records))
+	if _, err := output.WriteAt(header, 0); err != nil {
+		return 0, fmt.Errorf("error writing breach index: %v", err)
+	}
+
+	return records, nil
+}
+
+// offsetWriter adapts an io.WriterAt to a sequential io.Writer
+type offsetWriter struct {
+	w      io.WriterAt
+	offset int64
+}
+
+func (o *offsetWriter) Write(p []byte) (int, error) {
+	n, err := o.w.WriteAt(p, o.offset)
+	o.offset += int64(n)
+	return n, err
+}
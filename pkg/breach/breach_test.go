@@ -0,0 +1,127 @@
+package breach
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeBreachCorpus writes a sorted HASH:COUNT corpus for the given passwords
+func writeBreachCorpus(t *testing.T, counts map[string]int) string {
+	t.Helper()
+
+	var lines []string
+	for password, count := range counts {
+		hash := sha1.Sum([]byte(password))
+		lines = append(lines, fmt.Sprintf("%s:%d", strings.ToUpper(hex.EncodeToString(hash[:])), count))
+	}
+	sort.Strings(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDBCount(t *testing.T) {
+	counts := map[string]int{
+		"password":  9545824,
+		"123456":    37359195,
+		"Password1": 2413945,
+		"letmein":   3,
+	}
+	for i := 0; i < 200; i++ {
+		counts[fmt.Sprintf("filler-%d", i)] = i + 1
+	}
+	textPath := writeBreachCorpus(t, counts)
+
+	// Build an index from the same corpus
+	indexPath := filepath.Join(t.TempDir(), "pwned.pzdb")
+	in, _ := os.Open(textPath)
+	out, _ := os.Create(indexPath)
+	records, err := BuildIndex(in, out)
+	in.Close()
+	out.Close()
+	if err != nil {
+		t.Fatalf("Unexpected error building index: %v", err)
+	}
+	if records != int64(len(counts)) {
+		t.Errorf("Expected %d records, got %d", len(counts), records)
+	}
+
+	for _, path := range []string{textPath, indexPath} {
+		db, err := Open(path)
+		if err != nil {
+			t.Fatalf("Unexpected error opening %s: %v", path, err)
+		}
+
+		for password, want := range counts {
+			got, err := db.Count(password)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != want {
+				t.Errorf("Count(%q) in %s = %d, want %d", password, filepath.Base(path), got, want)
+			}
+		}
+
+		for _, password := range []string{"kX9#vQ2mLp7z", "", "zzzzzzzz"} {
+			if got, _ := db.Count(password); got != 0 {
+				t.Errorf("Count(%q) in %s = %d, want 0", password, filepath.Base(path), got)
+			}
+		}
+		db.Close()
+	}
+}
+
+func TestBuildIndexUnsorted(t *testing.T) {
+	input := strings.NewReader("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:1\n0000000000000000000000000000000000000000:2\n")
+	out, err := os.Create(filepath.Join(t.TempDir(), "index"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	if _, err := BuildIndex(input, out); err == nil {
+		t.Errorf("Expected error for unsorted input")
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		wantHash  string
+		wantCount int
+		wantErr   bool
+	}{
+		{"Valid line", "5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8:9545824", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8", 9545824, false},
+		{"Windows line ending", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:3\r", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8", 3, false},
+		{"Missing count", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8", "", 0, true},
+		{"Short hash", "5BAA61:3", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, count, err := parseLine(tt.line)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if hash != tt.wantHash || count != tt.wantCount {
+				t.Errorf("parseLine() = %s, %d, want %s, %d", hash, count, tt.wantHash, tt.wantCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Key derivation functions of Derive
+const (
+	KDFArgon2id = "argon2id"
+	KDFScrypt   = "scrypt"
+)
+
+// DeriveKDFs lists the supported key derivation functions, the default first
+var DeriveKDFs = []string{KDFArgon2id, KDFScrypt}
+
+// DeriveVersion is the latest version of the derivation scheme. A version
+// fixes the KDF parameters, the encoding of the inputs and the mapping of the
+// key to characters, so that a password derived once can be derived again
+// with any later release.
+const DeriveVersion = 1
+
+// Cost parameters of version 1: argon2id with 64 MiB and 3 passes (RFC 9106)
+// and scrypt with N=2^17, r=8, p=1 (128 MiB)
+const (
+	deriveArgon2Time    = 3
+	deriveArgon2Memory  = 64 * 1024
+	deriveArgon2Threads = 1
+	deriveScryptN       = 1 << 17
+	deriveScryptR       = 8
+	deriveScryptP       = 1
+	deriveKeyLength     = 32
+)
+
+// DeriveOptions controls how a site password is derived
+type DeriveOptions struct {
+	// Options sets the length, character set and class minimums. They are
+	// inputs of the derivation, the same password is only derived again
+	// with the same options.
+	Options Options
+
+	// Site and Login identify the account. The site is compared ignoring
+	// case and surrounding whitespace.
+	Site  string
+	Login string
+
+	// Counter is raised to change the password of an account
+	Counter uint32
+
+	// KDF is KDFArgon2id or KDFScrypt, KDFArgon2id when empty
+	KDF string
+
+	// Version is the derivation scheme, DeriveVersion when 0
+	Version int
+}
+
+// Derive deterministically derives the password of a site from a master
+// passphrase. The master passphrase is NFKC-normalized, so precomposed and
+// decomposed input typed on different machines agree, and stretched with a
+// memory-hard KDF salted with the site, login and counter, and the key is expanded with HKDF-SHA256
+// into the characters of the password, honoring the class minimums like
+// Generate. Nothing needs to be stored to derive the password again.
+func Derive(master string, opts DeriveOptions) (string, error) {
+	if opts.Version == 0 {
+		opts.Version = DeriveVersion
+	}
+	if opts.Version != 1 {
+		return "", fmt.Errorf("unsupported derivation version %d, expected 1 to %d", opts.Version, DeriveVersion)
+	}
+	master = analyzer.Normalize(master)
+	if master == "" {
+		return "", fmt.Errorf("master passphrase must not be empty")
+	}
+	site := strings.ToLower(strings.TrimSpace(opts.Site))
+	if site == "" {
+		return "", fmt.Errorf("site must not be empty")
+	}
+
+	charset, classes, err := opts.Options.charset()
+	if err != nil {
+		return "", err
+	}
+
+	salt := deriveSalt(opts.Version, site, opts.Login, opts.Counter)
+	var key []byte
+	switch opts.KDF {
+	case KDFArgon2id, "":
+		key = argon2.IDKey([]byte(master), salt, deriveArgon2Time, deriveArgon2Memory, deriveArgon2Threads, deriveKeyLength)
+	case KDFScrypt:
+		if key, err = scrypt.Key([]byte(master), salt, deriveScryptN, deriveScryptR, deriveScryptP, deriveKeyLength); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unknown KDF %q, expected one of: %s", opts.KDF, strings.Join(DeriveKDFs, ", "))
+	}
+
+	// The character rules are bound to the key stream, so that passwords of
+	// the same account under different rules are unrelated
+	info := fmt.Sprintf("password-zen derive v%d length=%d charset=%s", opts.Version, opts.Options.Length, charset)
+	for _, class := range classes {
+		info += fmt.Sprintf(" min-%s=%d", class.Name, class.Min)
+	}
+	stream := hkdf.Expand(sha256.New, key, []byte(info))
+
+	return compose(opts.Options.Length, charset, classes, func(n int) (int, error) {
+		return streamInt(stream, n)
+	})
+}
+
+// deriveSalt encodes the inputs of an account unambiguously: a version tag,
+// then site and login each prefixed with their length, then the counter, all
+// integers as 32 bit big endian
+func deriveSalt(version int, site, login string, counter uint32) []byte {
+	salt := []byte(fmt.Sprintf("password-zen derive v%d", version))
+	for _, field := range []string{site, login} {
+		salt = binary.BigEndian.AppendUint32(salt, uint32(len(field)))
+		salt = append(salt, field...)
+	}
+	return binary.BigEndian.AppendUint32(salt, counter)
+}
+
+// streamInt returns a uniform integer in [0, n) read from stream. Two bytes are
+// read at a time and values that would bias the result are rejected.
+func streamInt(stream io.Reader, n int) (int, error) {
+	if n <= 0 || n > 1<<16 {
+		return 0, fmt.Errorf("invalid range: %d", n)
+	}
+	limit := 1<<16 - (1<<16)%n
+	var b [2]byte
+	for {
+		if _, err := io.ReadFull(stream, b[:]); err != nil {
+			return 0, fmt.Errorf("key stream exhausted: %v", err)
+		}
+		if v := int(binary.BigEndian.Uint16(b[:])); v < limit {
+			return v % n, nil
+		}
+	}
+}
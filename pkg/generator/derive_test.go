@@ -0,0 +1,117 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
+)
+
+var deriveTestOptions = Options{Length: 20, IncludeDigits: true, IncludeSymbols: true, MinLower: 1, MinUpper: 1, MinDigits: 1, MinSymbols: 1}
+
+// The vectors pin version 1 of the scheme. They must never change, the scrypt
+// vector was checked against an independent implementation with Python's
+// hashlib.scrypt and hmac.
+func TestDeriveVectors(t *testing.T) {
+	tests := []struct {
+		kdf  string
+		want string
+	}{
+		{KDFArgon2id, "&U.)MzeqbJ:*|*QbHLB8"},
+		{KDFScrypt, "A4j)MhGI2lG<&O$k94p3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kdf, func(t *testing.T) {
+			got, err := Derive("correct horse battery staple", DeriveOptions{Options: deriveTestOptions, Site: "example.com", Login: "alice", Counter: 1, KDF: tt.kdf})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Derive() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeriveInputs(t *testing.T) {
+	base := DeriveOptions{Options: Options{Length: 16, IncludeDigits: true, MinDigits: 3}, Site: "Example.com", Login: "alice", Counter: 1}
+	derive := func(master string, opts DeriveOptions) string {
+		password, err := Derive(master, opts)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return password
+	}
+
+	first := derive("master", base)
+	if len(first) != 16 || countIn(first, analyzer.Digits) < 3 || strings.ContainsAny(first, analyzer.Symbols) {
+		t.Errorf("Password %q does not follow the character rules", first)
+	}
+
+	normalized := base
+	normalized.Site = "  EXAMPLE.COM "
+	if derive("master", normalized) != first {
+		t.Error("Site case and whitespace changed the password")
+	}
+
+	variations := map[string]func(*DeriveOptions){
+		"site":    func(o *DeriveOptions) { o.Site = "example.org" },
+		"login":   func(o *DeriveOptions) { o.Login = "bob" },
+		"counter": func(o *DeriveOptions) { o.Counter = 2 },
+		"length":  func(o *DeriveOptions) { o.Options.Length = 17 },
+		"minimum": func(o *DeriveOptions) { o.Options.MinDigits = 4 },
+	}
+	for name, vary := range variations {
+		opts := base
+		vary(&opts)
+		if derive("master", opts) == first {
+			t.Errorf("Changing the %s kept the password", name)
+		}
+	}
+	if derive("other master", base) == first {
+		t.Error("Changing the master passphrase kept the password")
+	}
+	if derive("caf\u00e9 cr\u00e8me", base) != derive("cafe\u0301 cre\u0300me", base) {
+		t.Error("Precomposed and decomposed master passphrases derived different passwords")
+	}
+
+	// Length prefixes keep the boundary between site and login
+	split := base
+	split.Site, split.Login = "example.coma", "lice"
+	if derive("master", split) == derive("master", DeriveOptions{Options: base.Options, Site: "example.com", Login: "alice", Counter: 1}) {
+		t.Error("Moving characters between site and login kept the password")
+	}
+}
+
+func TestDeriveErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		master string
+		opts   DeriveOptions
+	}{
+		{"Empty master", "", DeriveOptions{Options: Options{Length: 12}, Site: "example.com"}},
+		{"Empty site", "master", DeriveOptions{Options: Options{Length: 12}, Site: " "}},
+		{"Unknown KDF", "master", DeriveOptions{Options: Options{Length: 12}, Site: "example.com", KDF: "md5"}},
+		{"Unknown version", "master", DeriveOptions{Options: Options{Length: 12}, Site: "example.com", Version: 2}},
+		{"Minimum without class", "master", DeriveOptions{Options: Options{Length: 12, MinSymbols: 1}, Site: "example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Derive(tt.master, tt.opts); err == nil {
+				t.Error("Expected error but got none")
+			}
+		})
+	}
+}
+
+func countIn(password, chars string) int {
+	count := 0
+	for _, char := range password {
+		if strings.ContainsRune(chars, char) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,355 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+
+// Package generator generates random passwords and passphrases using crypto/rand
+// and derives deterministic site passwords from a master passphrase.
+// It is the engine behind 'password-zen generate' and 'password-zen derive'.
+//
+//	password, err := generator.Generate(generator.Options{
+//		Length:         16,
+//		IncludeDigits:  true,
+//		IncludeSymbols: true,
+//		MinDigits:      2,
+//	})
+package generator
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
+)
+
+// ambiguousChars are left out when Options.ExcludeAmbiguous is set
+const ambiguousChars = "il1Lo0O"
+
+// CharClass is a named group of characters with a minimum required count
+type CharClass struct {
+	Name  string
+	Chars string
+	Min   int
+}
+
+// ClassNames lists the character classes in charset order
+var ClassNames = []string{"lower", "upper", "digits", "symbols"}
+
+// Options controls how a password is generated
+type Options struct {
+	Length int
+
+	// IncludeDigits and IncludeSymbols add digits and analyzer.Symbols to the
+	// letters, ExcludeAmbiguous removes characters like l, 1 and O
+	IncludeDigits    bool
+	IncludeSymbols   bool
+	ExcludeAmbiguous bool
+
+	// Charset replaces the built-in character classes when set. It may
+	// contain multibyte characters but none that NFKC normalization changes,
+	// as the analyzer checks the normalized form of passwords, and so would
+	// systems that normalize before hashing as NIST SP 800-63B recommends.
+	Charset string
+
+	// Minimum number of characters of each class. A minimum for a class that
+	// is not part of the character set is an error.
+	MinLower   int
+	MinUpper   int
+	MinDigits  int
+	MinSymbols int
+}
+
+// Classes returns the character classes of the options' character set with
+// their minimums applied
+func (o Options) Classes() []CharClass {
+	var classes []CharClass
+	if o.Charset != "" {
+		classes = SplitCharClasses(o.Charset)
+	} else {
+		classes = CharClasses(o.IncludeDigits, o.IncludeSymbols, o.ExcludeAmbiguous)
+	}
+
+	for i := range classes {
+		classes[i].Min = o.minimum(classes[i].Name)
+	}
+	return classes
+}
+
+// minimum returns the minimum configured for the named class
+func (o Options) minimum(name string) int {
+	switch name {
+	case "lower":
+		return o.MinLower
+	case "upper":
+		return o.MinUpper
+	case "digits":
+		return o.MinDigits
+	case "symbols":
+		return o.MinSymbols
+	default:
+		return 0
+	}
+}
+
+// Generate generates a password that contains at least the configured minimum
+// of every character class
+func Generate(opts Options) (string, error) {
+	charset, classes, err := opts.charset()
+	if err != nil {
+		return "", err
+	}
+	return Compose(opts.Length, charset, classes)
+}
+
+// charset returns the character set and classes of the options, checking that
+// every class with a minimum is part of the character set
+func (o Options) charset() (string, []CharClass, error) {
+	classes := o.Classes()
+	charset := o.Charset
+	if charset == "" {
+		charset = JoinCharClasses(classes)
+	}
+	if charset == "" {
+		return "", nil, fmt.Errorf("no valid characters available for password generation")
+	}
+	for _, char := range charset {
+		if normalized := analyzer.Normalize(string(char)); normalized != string(char) {
+			return "", nil, fmt.Errorf("character %q of the character set normalizes to %q, use that instead", char, normalized)
+		}
+	}
+
+	for _, name := range ClassNames {
+		found := false
+		for _, class := range classes {
+			found = found || class.Name == name
+		}
+		if !found && o.minimum(name) > 0 {
+			return "", nil, fmt.Errorf("minimum %s requires %s in the character set", name, name)
+		}
+	}
+	return charset, classes, nil
+}
+
+// CharClasses returns the enabled built-in character classes
+func CharClasses(includeDigits, includeSymbols, excludeAmbiguous bool) []CharClass {
+	// Start with base letters
+	classes := []CharClass{
+		{Name: "lower", Chars: analyzer.Lowercase},
+		{Name: "upper", Chars: analyzer.Uppercase},
+	}
+
+	// Add digits if requested
+	if includeDigits {
+		classes = append(classes, CharClass{Name: "digits", Chars: analyzer.Digits})
+	}
+
+	// Add symbols if requested
+	if includeSymbols {
+		classes = append(classes, CharClass{Name: "symbols", Chars: analyzer.Symbols})
+	}
+
+	// Remove ambiguous characters if requested
+	if excludeAmbiguous {
+		for i := range classes {
+			for _, char := range ambiguousChars {
+				classes[i].Chars = strings.ReplaceAll(classes[i].Chars, string(char), "")
+			}
+		}
+	}
+
+	return classes
+}
+
+// Charset returns the joined characters of the enabled built-in classes
+func Charset(includeDigits, includeSymbols, excludeAmbiguous bool) string {
+	return JoinCharClasses(CharClasses(includeDigits, includeSymbols, excludeAmbiguous))
+}
+
+// SplitCharClasses groups the characters of a custom charset into the classes
+// recognised by the analyzer. Characters outside those classes are not grouped.
+func SplitCharClasses(charset string) []CharClass {
+	var lower, upper, digits, symbols strings.Builder
+	for _, char := range charset {
+		s := string(char)
+		switch {
+		case analyzer.ContainsLowercase(s):
+			lower.WriteRune(char)
+		case analyzer.ContainsUppercase(s):
+			upper.WriteRune(char)
+		case analyzer.ContainsDigit(s):
+			digits.WriteRune(char)
+		case analyzer.ContainsSymbol(s):
+			symbols.WriteRune(char)
+		}
+	}
+
+	var classes []CharClass
+	for i, chars := range []string{lower.String(), upper.String(), digits.String(), symbols.String()} {
+		if chars != "" {
+			classes = append(classes, CharClass{Name: ClassNames[i], Chars: chars})
+		}
+	}
+	return classes
+}
+
+// JoinCharClasses concatenates the characters of the classes
+func JoinCharClasses(classes []CharClass) string {
+	var charset string
+	for _, class := range classes {
+		charset += class.Chars
+	}
+	return charset
+}
+
+// Random generates a password of length characters drawn uniformly from
+// charset. Characters are code points, so charset may contain multibyte
+// characters such as é or ß.
+func Random(length int, charset string) (string, error) {
+	chars := []rune(charset)
+	if length <= 0 || len(chars) == 0 {
+		return "", fmt.Errorf("invalid parameters")
+	}
+	// use crypto/rand for secure random generation
+	result := make([]rune, length)
+	for i := range result {
+		index, err := randomInt(len(chars))
+		if err != nil {
+			return "", err
+		}
+		result[i] = chars[index]
+	}
+	return string(result), nil
+}
+
+// Compose generates a password from charset that contains at least class.Min
+// characters of every class. The required characters are drawn first, the rest
+// is filled from the whole charset and the result is shuffled so that no class
+// is tied to a position.
+func Compose(length int, charset string, classes []CharClass) (string, error) {
+	return compose(length, charset, classes, randomInt)
+}
+
+// compose implements Compose with intn as the source of uniform integers in
+// [0, n). Derive maps its key stream through compose, so any change to the
+// order in which intn is called changes derived passwords and requires a new
+// DeriveVersion.
+func compose(length int, charset string, classes []CharClass, intn func(n int) (int, error)) (string, error) {
+	chars := []rune(charset)
+	if length <= 0 || len(chars) == 0 {
+		return "", fmt.Errorf("invalid parameters")
+	}
+
+	required := 0
+	for _, class := range classes {
+		if class.Min < 0 {
+			return "", fmt.Errorf("minimum for %s must not be negative", class.Name)
+		}
+		if class.Min > 0 && len(class.Chars) == 0 {
+			return "", fmt.Errorf("no characters available for %s", class.Name)
+		}
+		required += class.Min
+	}
+	if required > length {
+		return "", fmt.Errorf("minimum character counts (%d) exceed password length (%d)", required, length)
+	}
+
+	result := make([]rune, 0, length)
+	for _, class := range classes {
+		classChars := []rune(class.Chars)
+		for i := 0; i < class.Min; i++ {
+			index, err := intn(len(classChars))
+			if err != nil {
+				return "", err
+			}
+			result = append(result, classChars[index])
+		}
+	}
+	for len(result) < length {
+		index, err := intn(len(chars))
+		if err != nil {
+			return "", err
+		}
+		result = append(result, chars[index])
+	}
+
+	// Fisher-Yates shuffle so required characters can land anywhere
+	for i := len(result) - 1; i > 0; i-- {
+		j, err := intn(i + 1)
+		if err != nil {
+			return "", err
+		}
+		result[i], result[j] = result[j], result[i]
+	}
+
+	return string(result), nil
+}
+
+// Entropy returns the entropy in bits of the passwords Compose draws with the
+// same arguments, taken as log2 of the number of passwords of the length over
+// charset with at least class.Min characters of every class. Characters of
+// charset outside the classes have no minimum. It returns 0 for invalid
+// arguments.
+func Entropy(length int, charset string, classes []CharClass) float64 {
+	charsetSize := utf8.RuneCountInString(charset)
+	if length <= 0 || charsetSize == 0 {
+		return 0
+	}
+
+	sizes, minimums := []int{}, []int{}
+	grouped := 0
+	for _, class := range classes {
+		classSize := utf8.RuneCountInString(class.Chars)
+		sizes, minimums = append(sizes, classSize), append(minimums, class.Min)
+		grouped += classSize
+	}
+	if other := charsetSize - grouped; other > 0 {
+		sizes, minimums = append(sizes, other), append(minimums, 0)
+	}
+
+	// ways[n] counts the passwords of n characters from the classes so far
+	// meeting their minimums, adding k characters of the next class at any
+	// k of the n+k positions
+	ways := make([]*big.Int, length+1)
+	ways[0] = big.NewInt(1)
+	for i, size := range sizes {
+		next := make([]*big.Int, length+1)
+		for n, count := range ways {
+			if count == nil {
+				continue
+			}
+			for k := max(minimums[i], 0); n+k <= length; k++ {
+				term := new(big.Int).Binomial(int64(n+k), int64(k))
+				term.Mul(term, new(big.Int).Exp(big.NewInt(int64(size)), big.NewInt(int64(k)), nil))
+				term.Mul(term, count)
+				if next[n+k] == nil {
+					next[n+k] = new(big.Int)
+				}
+				next[n+k].Add(next[n+k], term)
+			}
+		}
+		ways = next
+	}
+	if ways[length] == nil || ways[length].Sign() == 0 {
+		return 0
+	}
+
+	mantissa := new(big.Float)
+	exponent := new(big.Float).SetInt(ways[length]).MantExp(mantissa)
+	m, _ := mantissa.Float64()
+	return float64(exponent) + math.Log2(m)
+}
+
+// randomInt returns a uniformly distributed integer in [0, n) using crypto/rand
+func randomInt(n int) (int, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid range: %d", n)
+	}
+	index, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("error generating random index: %v", err)
+	}
+	return int(index.Int64()), nil
+}
@@ -0,0 +1,294 @@
+package generator
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
+)
+
+func TestCharset(t *testing.T) {
+	tests := []struct {
+		name              string
+		includeDigits     bool
+		includeSymbols    bool
+		excludeAmbiguous  bool
+		expectContains    []string
+		expectNotContains []string
+	}{
+		{
+			name:           "Basic charset with digits",
+			includeDigits:  true,
+			includeSymbols: false,
+			expectContains: []string{"a", "Z", "0", "9"},
+		},
+		{
+			name:           "Charset with symbols",
+			includeDigits:  true,
+			includeSymbols: true,
+			expectContains: []string{"a", "Z", "0", "!", "@"},
+		},
+		{
+			name:              "Exclude ambiguous characters",
+			includeDigits:     true,
+			includeSymbols:    false,
+			excludeAmbiguous:  true,
+			expectNotContains: []string{"i", "l", "1", "L", "o", "0", "O"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			charset := Charset(tt.includeDigits, tt.includeSymbols, tt.excludeAmbiguous)
+
+			for _, char := range tt.expectContains {
+				if !strings.Contains(charset, char) {
+					t.Errorf("Expected charset to contain '%s', but it didn't. Charset: %s", char, charset)
+				}
+			}
+
+			for _, char := range tt.expectNotContains {
+				if strings.Contains(charset, char) {
+					t.Errorf("Expected charset to NOT contain '%s', but it did. Charset: %s", char, charset)
+				}
+			}
+		})
+	}
+}
+
+func TestRandom(t *testing.T) {
+	tests := []struct {
+		name    string
+		length  int
+		charset string
+		wantErr bool
+	}{
+		{
+			name:    "Valid password generation",
+			length:  12,
+			charset: "abcdefghijklmnopqrstuvwxyz",
+			wantErr: false,
+		},
+		{
+			name:    "Multibyte charset",
+			length:  12,
+			charset: "äöüÄÖÜß€",
+			wantErr: false,
+		},
+		{
+			name:    "Zero length should error",
+			length:  0,
+			charset: "abc",
+			wantErr: true,
+		},
+		{
+			name:    "Empty charset should error",
+			length:  12,
+			charset: "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password, err := Random(tt.length, tt.charset)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+
+			if got := utf8.RuneCountInString(password); got != tt.length {
+				t.Errorf("Expected password length %d, got %d", tt.length, got)
+			}
+
+			// Check that all characters in password are from charset
+			for _, char := range password {
+				if !strings.Contains(tt.charset, string(char)) {
+					t.Errorf("Password contains character '%c' not in charset '%s'", char, tt.charset)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitCharClasses(t *testing.T) {
+	classes := SplitCharClasses("abcéXYZÜ123٣!@ ~€")
+
+	want := map[string]string{"lower": "abcé", "upper": "XYZÜ", "digits": "123٣", "symbols": "!@~€"}
+	if len(classes) != len(want) {
+		t.Fatalf("Expected %d classes, got %d: %v", len(want), len(classes), classes)
+	}
+	for _, class := range classes {
+		if class.Chars != want[class.Name] {
+			t.Errorf("Class %s = %q, want %q", class.Name, class.Chars, want[class.Name])
+		}
+	}
+}
+
+func TestCompose(t *testing.T) {
+	tests := []struct {
+		name    string
+		length  int
+		classes []CharClass
+		wantErr bool
+	}{
+		{
+			name:    "One of each class",
+			length:  4,
+			classes: withMinimums(CharClasses(true, true, false), 1),
+		},
+		{
+			name:    "Several of each class",
+			length:  16,
+			classes: withMinimums(CharClasses(true, true, true), 3),
+		},
+		{
+			name:    "Minimums exceed length",
+			length:  3,
+			classes: withMinimums(CharClasses(true, true, false), 1),
+			wantErr: true,
+		},
+		{
+			name:    "Negative minimum",
+			length:  8,
+			classes: withMinimums(CharClasses(true, false, false), -1),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			charset := JoinCharClasses(tt.classes)
+
+			// Repeat to make a lucky pass unlikely
+			for run := 0; run < 50; run++ {
+				password, err := Compose(tt.length, charset, tt.classes)
+
+				if tt.wantErr {
+					if err == nil {
+						t.Errorf("Expected error but got none")
+					}
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				if len(password) != tt.length {
+					t.Errorf("Expected password length %d, got %d", tt.length, len(password))
+				}
+
+				for _, class := range tt.classes {
+					count := 0
+					for _, char := range password {
+						if strings.ContainsRune(class.Chars, char) {
+							count++
+						}
+					}
+					if count < class.Min {
+						t.Errorf("Password %q has %d %s, want at least %d", password, count, class.Name, class.Min)
+					}
+				}
+
+				// The result must pass the matching analyze checks
+				if !analyzer.ContainsLowercase(password) || !analyzer.ContainsUppercase(password) || !analyzer.ContainsDigit(password) {
+					t.Errorf("Password %q fails analyze checks", password)
+				}
+			}
+		})
+	}
+}
+
+func withMinimums(classes []CharClass, min int) []CharClass {
+	for i := range classes {
+		classes[i].Min = min
+	}
+	return classes
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"Default classes", Options{Length: 12, IncludeDigits: true, MinLower: 1, MinUpper: 1, MinDigits: 1}, false},
+		{"Custom charset", Options{Length: 8, Charset: "ab12", MinDigits: 2}, false},
+		{"Minimum for missing class", Options{Length: 12, MinSymbols: 1}, true},
+		{"Charset outside the classes", Options{Length: 12, Charset: "~ "}, false},
+		{"Multibyte charset", Options{Length: 12, Charset: "äöüÄÖÜ٣€", MinUpper: 2, MinDigits: 1, MinSymbols: 1}, false},
+		{"Charset changed by NFKC", Options{Length: 12, Charset: "ａｂｃ"}, true},
+		{"Zero length", Options{Length: 0}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password, err := Generate(tt.opts)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := utf8.RuneCountInString(password); got != tt.opts.Length {
+				t.Errorf("Expected password length %d, got %d", tt.opts.Length, got)
+			}
+			for _, class := range tt.opts.Classes() {
+				if countChars(password, class.Chars) < class.Min {
+					t.Errorf("Password %q has too few %s", password, class.Name)
+				}
+			}
+		})
+	}
+}
+
+func countChars(password, chars string) int {
+	count := 0
+	for _, char := range password {
+		if strings.ContainsRune(chars, char) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestEntropy(t *testing.T) {
+	tests := []struct {
+		name    string
+		length  int
+		charset string
+		classes []CharClass
+		want    float64
+	}{
+		{"No minimums", 12, Charset(true, false, false), nil, 12 * math.Log2(62)},
+		// 2^3 strings of a and b, less the one without b
+		{"Minimum excludes strings", 3, "ab", []CharClass{{Name: "lower", Chars: "a"}, {Name: "upper", Chars: "b", Min: 1}}, math.Log2(7)},
+		// Two digits out of three characters: 3 positions * 10^2 * 26 + 10^3
+		{"Ungrouped characters", 3, "0123456789abcdefghijklmnopqrstuvwxyz", []CharClass{{Name: "digits", Chars: "0123456789", Min: 2}}, math.Log2(3*100*26 + 1000)},
+		{"Unsatisfiable", 2, "ab", []CharClass{{Name: "lower", Chars: "ab", Min: 3}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Entropy(tt.length, tt.charset, tt.classes); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Entropy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package generator
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
+)
+
+// effLargeWordlist is the EFF large wordlist (7776 words, five dice per word)
+//
+//go:embed wordlists/eff_large_wordlist.txt
+var effLargeWordlist string
+
+var defaultWordlist = sync.OnceValue(func() []string {
+	return ParseWordlist(effLargeWordlist)
+})
+
+// PassphraseOptions controls how a passphrase is assembled
+type PassphraseOptions struct {
+	Words        int
+	Separator    string
+	Capitalize   bool
+	AppendDigit  bool
+	AppendSymbol bool
+
+	// Wordlist to pick words from, the embedded EFF large wordlist when empty
+	Wordlist []string
+}
+
+// wordlist returns the wordlist words are picked from
+func (o PassphraseOptions) wordlist() []string {
+	if len(o.Wordlist) == 0 {
+		return defaultWordlist()
+	}
+	return o.Wordlist
+}
+
+// DefaultWordlist returns a copy of the embedded EFF large wordlist
+func DefaultWordlist() []string {
+	return append([]string(nil), defaultWordlist()...)
+}
+
+// ParseWordlist extracts unique words from wordlist data.
+// Lines may be plain words or diceware style "11111<TAB>word" entries;
+// empty lines and lines starting with '#' are skipped.
+func ParseWordlist(data string) []string {
+	seen := make(map[string]bool)
+	var words []string
+
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		word := fields[len(fields)-1]
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+
+	return words
+}
+
+// LoadWordlist reads a wordlist file in the format accepted by ParseWordlist
+func LoadWordlist(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read file: %v", err)
+	}
+
+	words := ParseWordlist(string(data))
+	if len(words) < 2 {
+		return nil, fmt.Errorf("wordlist must contain at least 2 unique words, found %d", len(words))
+	}
+	return words, nil
+}
+
+// Passphrase picks opts.Words words uniformly from the wordlist using crypto/rand
+func Passphrase(opts PassphraseOptions) (string, error) {
+	wordlist := opts.wordlist()
+	if opts.Words <= 0 || len(wordlist) == 0 {
+		return "", fmt.Errorf("invalid parameters")
+	}
+
+	chosen := make([]string, opts.Words)
+	for i := range chosen {
+		index, err := randomInt(len(wordlist))
+		if err != nil {
+			return "", err
+		}
+		word := wordlist[index]
+		if opts.Capitalize {
+			word = capitalizeWord(word)
+		}
+		chosen[i] = word
+	}
+	passphrase := strings.Join(chosen, opts.Separator)
+
+	if opts.AppendDigit {
+		index, err := randomInt(len(analyzer.Digits))
+		if err != nil {
+			return "", err
+		}
+		passphrase += string(analyzer.Digits[index])
+	}
+
+	if opts.AppendSymbol {
+		index, err := randomInt(len(analyzer.Symbols))
+		if err != nil {
+			return "", err
+		}
+		passphrase += string(analyzer.Symbols[index])
+	}
+
+	return passphrase, nil
+}
+
+// PassphraseEntropy returns the entropy in bits of a passphrase built with opts.
+// Capitalization and separators are deterministic and do not add entropy.
+func PassphraseEntropy(opts PassphraseOptions) float64 {
+	wordlistSize := len(opts.wordlist())
+	if wordlistSize <= 0 || opts.Words <= 0 {
+		return 0
+	}
+
+	entropy := float64(opts.Words) * math.Log2(float64(wordlistSize))
+	if opts.AppendDigit {
+		entropy += math.Log2(float64(len(analyzer.Digits)))
+	}
+	if opts.AppendSymbol {
+		entropy += math.Log2(float64(len(analyzer.Symbols)))
+	}
+	return entropy
+}
+
+func capitalizeWord(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+	if r == utf8.RuneError {
+		return word
+	}
+	return string(unicode.ToUpper(r)) + word[size:]
+}
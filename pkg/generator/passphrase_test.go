@@ -0,0 +1,119 @@
+package generator
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
+)
+
+func TestParseWordlist(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{"Plain words", "alpha\nbravo\ncharlie\n", []string{"alpha", "bravo", "charlie"}},
+		{"Diceware format", "11111\talpha\n11112\tbravo\n", []string{"alpha", "bravo"}},
+		{"Skips blanks, comments and duplicates", "# list\n\nalpha\nalpha\n  bravo  \n", []string{"alpha", "bravo"}},
+		{"Empty data", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseWordlist(tt.data)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ParseWordlist() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbeddedWordlist(t *testing.T) {
+	words := DefaultWordlist()
+	if len(words) != 7776 {
+		t.Errorf("Expected 7776 words in embedded EFF wordlist, got %d", len(words))
+	}
+}
+
+func TestPassphrase(t *testing.T) {
+	wordlist := []string{"alpha", "bravo", "charlie", "delta"}
+
+	tests := []struct {
+		name    string
+		opts    PassphraseOptions
+		wantErr bool
+	}{
+		{"Basic passphrase", PassphraseOptions{Words: 4, Separator: "-", Wordlist: wordlist}, false},
+		{"Capitalized with digit and symbol", PassphraseOptions{Words: 3, Separator: " ", Capitalize: true, AppendDigit: true, AppendSymbol: true, Wordlist: wordlist}, false},
+		{"Zero words should error", PassphraseOptions{Words: 0, Separator: "-", Wordlist: wordlist}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			passphrase, err := Passphrase(tt.opts)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+
+			body := passphrase
+			if tt.opts.AppendSymbol {
+				if !strings.ContainsAny(body[len(body)-1:], analyzer.Symbols) {
+					t.Errorf("Expected passphrase to end with a symbol: %s", passphrase)
+				}
+				body = body[:len(body)-1]
+			}
+			if tt.opts.AppendDigit {
+				if !strings.ContainsAny(body[len(body)-1:], analyzer.Digits) {
+					t.Errorf("Expected passphrase to end with a digit: %s", passphrase)
+				}
+				body = body[:len(body)-1]
+			}
+
+			parts := strings.Split(body, tt.opts.Separator)
+			if len(parts) != tt.opts.Words {
+				t.Fatalf("Expected %d words, got %d: %s", tt.opts.Words, len(parts), passphrase)
+			}
+			for _, part := range parts {
+				if tt.opts.Capitalize && !unicode.IsUpper(rune(part[0])) {
+					t.Errorf("Expected word '%s' to be capitalized", part)
+				}
+				if !strings.Contains(strings.Join(wordlist, ","), strings.ToLower(part)) {
+					t.Errorf("Word '%s' is not from the wordlist", part)
+				}
+			}
+		})
+	}
+}
+
+func TestPassphraseEntropy(t *testing.T) {
+	tests := []struct {
+		name string
+		opts PassphraseOptions
+		want float64
+	}{
+		{"Six EFF words", PassphraseOptions{Words: 6}, 6 * math.Log2(7776)},
+		{"Capitalization adds nothing", PassphraseOptions{Words: 6, Capitalize: true}, 6 * math.Log2(7776)},
+		{"Appended digit and symbol", PassphraseOptions{Words: 2, AppendDigit: true, AppendSymbol: true, Wordlist: make([]string, 1024)}, 20 + math.Log2(10) + math.Log2(float64(len(analyzer.Symbols)))},
+		{"No words", PassphraseOptions{Words: 0}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PassphraseEntropy(tt.opts); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("PassphraseEntropy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package generator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
+)
+
+// Letters of the consonant and vowel placeholders of patterns
+const (
+	patternConsonants = "bcdfghjklmnpqrstvwxyz"
+	patternVowels     = "aeiou"
+)
+
+// maskSymbols are the 33 characters of ?s in hashcat: the printable ASCII
+// characters that are neither letters nor digits, space included
+const maskSymbols = " !\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+// PatternPlaceholders are the characters of a pattern that stand for a random
+// character of a set. Every other character is a literal.
+var PatternPlaceholders = map[rune]string{
+	'c': patternConsonants,
+	'C': strings.ToUpper(patternConsonants),
+	'v': patternVowels,
+	'V': strings.ToUpper(patternVowels),
+	'l': analyzer.Lowercase,
+	'u': analyzer.Uppercase,
+	'L': analyzer.Lowercase + analyzer.Uppercase,
+	'9': analyzer.Digits,
+	'#': analyzer.Symbols,
+	'a': analyzer.Lowercase + analyzer.Uppercase + analyzer.Digits,
+	'*': analyzer.Lowercase + analyzer.Uppercase + analyzer.Digits + analyzer.Symbols,
+}
+
+// MaskPlaceholders are the placeholders of hashcat masks, written as ?l, ?u
+// and so on, with the same characters as in hashcat. ?? is a literal question
+// mark.
+var MaskPlaceholders = map[rune]string{
+	'l': analyzer.Lowercase,
+	'u': analyzer.Uppercase,
+	'd': analyzer.Digits,
+	's': maskSymbols,
+	'a': analyzer.Lowercase + analyzer.Uppercase + analyzer.Digits + maskSymbols,
+	'h': "0123456789abcdef",
+	'H': "0123456789ABCDEF",
+}
+
+// PatternOptions controls how a pattern is parsed
+type PatternOptions struct {
+	// Placeholders defines custom placeholders, usable in the pattern as the
+	// character itself or in a mask as ? followed by it, e.g. ?1 as with the
+	// custom charsets of hashcat. They replace the built-in placeholders of
+	// the same name. Like in hashcat, their characters may include the
+	// built-in placeholders of masks, such as ?l?d, and ?? for a question
+	// mark.
+	Placeholders map[rune]string
+
+	// ExcludeAmbiguous removes characters like l, 1 and O from the built-in
+	// placeholders
+	ExcludeAmbiguous bool
+}
+
+// Pattern generates passwords of a fixed shape, drawing every position from
+// its own set of characters. Literals are sets of one character.
+type Pattern struct {
+	positions [][]rune
+}
+
+// ParsePattern parses a pattern such as Cvccvc-99-## of PatternPlaceholders,
+// a hashcat mask such as ?u?l?l?d?d?s of MaskPlaceholders, or a mix of both.
+// A backslash makes the next character a literal, so \l stands for l.
+func ParsePattern(pattern string, opts PatternOptions) (Pattern, error) {
+	builtinSet := func(builtin map[rune]string, name rune) (string, bool) {
+		chars, ok := builtin[name]
+		if ok && opts.ExcludeAmbiguous {
+			chars = removeAmbiguous(chars)
+		}
+		return chars, ok
+	}
+	custom := make(map[rune]string, len(opts.Placeholders))
+	for name, chars := range opts.Placeholders {
+		expanded, err := expandMask(chars, func(name rune) (string, bool) { return builtinSet(MaskPlaceholders, name) })
+		if err != nil {
+			return Pattern{}, fmt.Errorf("placeholder %c: %v", name, err)
+		}
+		custom[name] = expanded
+	}
+	sets := func(builtin map[rune]string, name rune) (string, bool) {
+		if chars, ok := custom[name]; ok {
+			return chars, true
+		}
+		return builtinSet(builtin, name)
+	}
+
+	var p Pattern
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		char := runes[i]
+		chars := string(char)
+		switch char {
+		case '\\':
+			if i++; i == len(runes) {
+				return Pattern{}, fmt.Errorf("pattern ends with an unfinished escape \\")
+			}
+			chars = string(runes[i])
+		case '?':
+			if i++; i == len(runes) {
+				return Pattern{}, fmt.Errorf("pattern ends with an unfinished placeholder ?")
+			}
+			if runes[i] != '?' {
+				var ok bool
+				if chars, ok = sets(MaskPlaceholders, runes[i]); !ok {
+					return Pattern{}, fmt.Errorf("unknown placeholder ?%c at position %d", runes[i], len(p.positions)+1)
+				}
+			}
+		default:
+			if set, ok := sets(PatternPlaceholders, char); ok {
+				chars = set
+			}
+		}
+
+		set, err := placeholderSet(chars)
+		if err != nil {
+			return Pattern{}, fmt.Errorf("position %d of the pattern: %v", len(p.positions)+1, err)
+		}
+		p.positions = append(p.positions, set)
+	}
+	if len(p.positions) == 0 {
+		return Pattern{}, fmt.Errorf("pattern is empty")
+	}
+	return p, nil
+}
+
+// expandMask replaces the ?x placeholders of the characters of a custom
+// placeholder with the characters of set(x), and ?? with a question mark
+func expandMask(chars string, set func(name rune) (string, bool)) (string, error) {
+	var expanded strings.Builder
+	runes := []rune(chars)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '?' {
+			expanded.WriteRune(runes[i])
+			continue
+		}
+		if i++; i == len(runes) {
+			return "", fmt.Errorf("unfinished placeholder ?, use ?? for a question mark")
+		}
+		if runes[i] == '?' {
+			expanded.WriteRune('?')
+			continue
+		}
+		placeholder, ok := set(runes[i])
+		if !ok {
+			return "", fmt.Errorf("unknown placeholder ?%c", runes[i])
+		}
+		expanded.WriteString(placeholder)
+	}
+	return expanded.String(), nil
+}
+
+// placeholderSet returns the distinct characters of a set, so that each is
+// drawn with the same probability
+func placeholderSet(chars string) ([]rune, error) {
+	var set []rune
+	seen := make(map[rune]bool)
+	for _, char := range chars {
+		if normalized := analyzer.Normalize(string(char)); normalized != string(char) {
+			return nil, fmt.Errorf("character %q normalizes to %q, use that instead", char, normalized)
+		}
+		if !seen[char] {
+			seen[char] = true
+			set = append(set, char)
+		}
+	}
+	if len(set) == 0 {
+		return nil, fmt.Errorf("no characters available")
+	}
+	return set, nil
+}
+
+// Len returns the length of the passwords of the pattern in characters
+func (p Pattern) Len() int {
+	return len(p.positions)
+}
+
+// Generate generates a password of the pattern, drawing every position
+// uniformly from its set with crypto/rand
+func (p Pattern) Generate() (string, error) {
+	if len(p.positions) == 0 {
+		return "", fmt.Errorf("invalid parameters")
+	}
+	result := make([]rune, len(p.positions))
+	for i, set := range p.positions {
+		index, err := randomInt(len(set))
+		if err != nil {
+			return "", err
+		}
+		result[i] = set[index]
+	}
+	return string(result), nil
+}
+
+// Entropy returns the exact entropy in bits of the passwords of the pattern,
+// the sum of log2 of the size of every position's set. Literals add nothing.
+func (p Pattern) Entropy() float64 {
+	var entropy float64
+	for _, set := range p.positions {
+		entropy += math.Log2(float64(len(set)))
+	}
+	return entropy
+}
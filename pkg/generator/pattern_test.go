@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		opts    PatternOptions
+		match   string
+		entropy float64
+	}{
+		{"Shorthand", "Cvccvc-99-##", PatternOptions{}, `^[B-DF-HJ-NP-TV-Z][aeiou][b-df-hj-np-tv-z]{2}[aeiou][b-df-hj-np-tv-z]-\d\d-[[:punct:]]{2}$`, 4*math.Log2(21) + 2*math.Log2(5) + 2*math.Log2(10) + 2*math.Log2(27)},
+		{"Hashcat mask", "?u?l?l?d?d?s", PatternOptions{}, `^[A-Z][a-z]{2}\d\d[ [:punct:]]$`, math.Log2(26) + 2*math.Log2(26) + 2*math.Log2(10) + math.Log2(33)},
+		{"Hashcat all", "?a", PatternOptions{}, `^[ -~]$`, math.Log2(95)},
+		{"Hex", "?h?h?H?H", PatternOptions{}, `^[0-9a-f]{2}[0-9A-F]{2}$`, 16},
+		{"Escapes", `\l\?x??-\\`, PatternOptions{}, `^l\?x\?-\\$`, 0},
+		{"Custom placeholders", "?1?1K", PatternOptions{Placeholders: map[rune]string{'1': "ab", 'K': "xyzz"}}, `^[ab]{2}[xyz]$`, 2 + math.Log2(3)},
+		{"Placeholders in definitions", "?1?2", PatternOptions{Placeholders: map[rune]string{'1': "?l?d", '2': "?d??"}}, `^[a-z0-9][0-9?]$`, math.Log2(36) + math.Log2(11)},
+		{"Override", "9999", PatternOptions{Placeholders: map[rune]string{'9': "01"}}, `^[01]{4}$`, 4},
+		{"Exclude ambiguous", "lllllllllllllllllllll", PatternOptions{ExcludeAmbiguous: true}, `^[a-hj-km-np-z]{21}$`, 21 * math.Log2(23)},
+		{"Unicode", "€ü?d", PatternOptions{Placeholders: map[rune]string{'ü': "äöü"}}, `^€[äöü]\d$`, math.Log2(3) + math.Log2(10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern, err := ParsePattern(tt.pattern, tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := pattern.Entropy(); math.Abs(got-tt.entropy) > 1e-9 {
+				t.Errorf("Entropy() = %v, want %v", got, tt.entropy)
+			}
+			match := regexp.MustCompile(tt.match)
+			for i := 0; i < 50; i++ {
+				password, err := pattern.Generate()
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if !match.MatchString(password) || len([]rune(password)) != pattern.Len() {
+					t.Fatalf("Password %q does not match %s", password, tt.match)
+				}
+			}
+		})
+	}
+}
+
+func TestParsePatternInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		opts    PatternOptions
+		want    string
+	}{
+		{"Empty", "", PatternOptions{}, "empty"},
+		{"Unknown mask placeholder", "?u?x", PatternOptions{}, "unknown placeholder ?x at position 2"},
+		{"Hashcat bytes", "?b", PatternOptions{}, "unknown placeholder ?b"},
+		{"Unfinished mask", "?u?", PatternOptions{}, "unfinished placeholder"},
+		{"Unfinished escape", `ab\`, PatternOptions{}, "unfinished escape"},
+		{"Empty placeholder", "X", PatternOptions{Placeholders: map[rune]string{'X': ""}}, "no characters"},
+		{"Unknown placeholder in definition", "?1", PatternOptions{Placeholders: map[rune]string{'1': "?l?x"}}, "placeholder 1: unknown placeholder ?x"},
+		{"Unfinished placeholder in definition", "?1", PatternOptions{Placeholders: map[rune]string{'1': "ab?"}}, "use ?? for a question mark"},
+		{"Not normalized", "X", PatternOptions{Placeholders: map[rune]string{'X': "ａb"}}, "normalizes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParsePattern(tt.pattern, tt.opts); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParsePattern(%q) error = %v, want one containing %q", tt.pattern, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package generator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
+)
+
+// Models of pronounceable passwords
+const (
+	// ModelSyllables alternates uniformly chosen consonants and vowels
+	ModelSyllables = "syllables"
+	// ModelTrigrams picks every letter by its frequency after the previous two
+	// letters in the words of the embedded EFF wordlist
+	ModelTrigrams = "trigrams"
+)
+
+// PronounceableModels lists the supported models
+var PronounceableModels = []string{ModelSyllables, ModelTrigrams}
+
+// Letters of the syllable model. c, q, w, x and y are left out because they
+// are hard to spell out or sound like other letters.
+const (
+	syllableConsonants = "bdfghjklmnprstvz"
+	syllableVowels     = "aeiou"
+)
+
+// PronounceableOptions controls how a pronounceable password is generated
+type PronounceableOptions struct {
+	// Length is the total length including digits and symbols
+	Length int
+
+	// Model is ModelSyllables or ModelTrigrams, ModelSyllables when empty
+	Model string
+
+	// ExcludeAmbiguous leaves out letters like l and o
+	ExcludeAmbiguous bool
+
+	// Number of letters turned uppercase and of digits and analyzer.Symbols
+	// inserted at random positions
+	Upper   int
+	Digits  int
+	Symbols int
+}
+
+// Pronounceable generates a password from the letters of a pronounceable model
+// with digits and symbols inserted at random positions. It returns the
+// password with its entropy in bits, computed from the probability of the
+// model producing it rather than from its length.
+func Pronounceable(opts PronounceableOptions) (string, float64, error) {
+	if opts.Upper < 0 || opts.Digits < 0 || opts.Symbols < 0 {
+		return "", 0, fmt.Errorf("character counts must not be negative")
+	}
+	letterCount := opts.Length - opts.Digits - opts.Symbols
+	if letterCount <= 0 {
+		return "", 0, fmt.Errorf("digits and symbols (%d) leave no letters in a password of length %d", opts.Digits+opts.Symbols, opts.Length)
+	}
+	if opts.Upper > letterCount {
+		return "", 0, fmt.Errorf("uppercase letters (%d) exceed the number of letters (%d)", opts.Upper, letterCount)
+	}
+
+	var letters []byte
+	var entropy float64
+	var err error
+	switch opts.Model {
+	case ModelSyllables, "":
+		letters, entropy, err = syllableLetters(letterCount, opts.ExcludeAmbiguous)
+	case ModelTrigrams:
+		letters, entropy, err = trigramLetters(letterCount, opts.ExcludeAmbiguous)
+	default:
+		return "", 0, fmt.Errorf("unknown model %q, expected one of: %s", opts.Model, strings.Join(PronounceableModels, ", "))
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	// Uppercase letters take distinct positions
+	positions, err := randomPositions(letterCount, opts.Upper)
+	if err != nil {
+		return "", 0, err
+	}
+	for _, position := range positions {
+		letters[position] -= 'a' - 'A'
+	}
+	entropy += log2Binomial(letterCount, opts.Upper)
+
+	// Digits and symbols are shuffled together and placed at distinct positions
+	digits, symbols := analyzer.Digits, analyzer.Symbols
+	if opts.ExcludeAmbiguous {
+		digits = removeAmbiguous(digits)
+	}
+	var inserted []byte
+	for _, class := range []struct {
+		chars string
+		count int
+	}{{digits, opts.Digits}, {symbols, opts.Symbols}} {
+		for i := 0; i < class.count; i++ {
+			index, err := randomInt(len(class.chars))
+			if err != nil {
+				return "", 0, err
+			}
+			inserted = append(inserted, class.chars[index])
+		}
+		entropy += float64(class.count) * math.Log2(float64(len(class.chars)))
+	}
+	if err := shuffle(inserted); err != nil {
+		return "", 0, err
+	}
+	entropy += log2Binomial(len(inserted), opts.Digits)
+
+	if positions, err = randomPositions(opts.Length, len(inserted)); err != nil {
+		return "", 0, err
+	}
+	entropy += log2Binomial(opts.Length, len(inserted))
+
+	result := make([]byte, 0, opts.Length)
+	for i := 0; i < opts.Length; i++ {
+		if len(positions) > 0 && positions[0] == i {
+			result = append(result, inserted[0])
+			positions, inserted = positions[1:], inserted[1:]
+		} else {
+			result = append(result, letters[0])
+			letters = letters[1:]
+		}
+	}
+
+	return string(result), entropy, nil
+}
+
+// syllableLetters alternates consonants and vowels, starting with a consonant
+func syllableLetters(count int, excludeAmbiguous bool) ([]byte, float64, error) {
+	sets := [2]string{syllableConsonants, syllableVowels}
+	if excludeAmbiguous {
+		sets = [2]string{removeAmbiguous(syllableConsonants), removeAmbiguous(syllableVowels)}
+	}
+
+	letters := make([]byte, count)
+	var entropy float64
+	for i := range letters {
+		set := sets[i%2]
+		index, err := randomInt(len(set))
+		if err != nil {
+			return nil, 0, err
+		}
+		letters[i] = set[index]
+		entropy += math.Log2(float64(len(set)))
+	}
+	return letters, entropy, nil
+}
+
+// trigramModel counts the letters following every pair of letters. Words
+// start with the context "^^".
+type trigramModel map[string]map[byte]int
+
+var defaultTrigramModel = sync.OnceValue(func() trigramModel {
+	return trainTrigrams(defaultWordlist())
+})
+
+// trainTrigrams builds a trigram model from the lowercase ASCII words of words
+func trainTrigrams(words []string) trigramModel {
+	model := make(trigramModel)
+	for _, word := range words {
+		if strings.Trim(word, analyzer.Lowercase) != "" {
+			continue
+		}
+		context := "^^"
+		for _, next := range []byte(word) {
+			if model[context] == nil {
+				model[context] = make(map[byte]int)
+			}
+			model[context][next]++
+			context = context[1:] + string(next)
+		}
+	}
+	return model
+}
+
+// trigramLetters walks the trigram model. When no letter can follow, a new
+// word starts without a visible boundary. The restart is not a random choice,
+// so every password has a single path through the model and the entropy is
+// exactly the improbability of the password.
+func trigramLetters(count int, excludeAmbiguous bool) ([]byte, float64, error) {
+	model := defaultTrigramModel()
+
+	letters := make([]byte, 0, count)
+	var entropy float64
+	context := "^^"
+	for len(letters) < count {
+		// Sort the candidates so that the same random number picks the same letter
+		var candidates []byte
+		total := 0
+		for next, n := range model[context] {
+			if excludeAmbiguous && strings.IndexByte(ambiguousChars, next) >= 0 {
+				continue
+			}
+			candidates = append(candidates, next)
+			total += n
+		}
+		if total == 0 {
+			context = "^^"
+			continue
+		}
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i] < candidates[j] })
+
+		pick, err := randomInt(total)
+		if err != nil {
+			return nil, 0, err
+		}
+		var next byte
+		for _, candidate := range candidates {
+			if pick < model[context][candidate] {
+				next = candidate
+				break
+			}
+			pick -= model[context][candidate]
+		}
+		entropy -= math.Log2(float64(model[context][next]) / float64(total))
+
+		letters = append(letters, next)
+		context = context[1:] + string(next)
+	}
+	return letters, entropy, nil
+}
+
+// randomPositions picks k distinct positions in [0, n), sorted
+func randomPositions(n, k int) ([]int, error) {
+	indexes := make([]int, n)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	// Partial Fisher-Yates shuffle of the first k positions
+	for i := 0; i < k; i++ {
+		j, err := randomInt(n - i)
+		if err != nil {
+			return nil, err
+		}
+		indexes[i], indexes[i+j] = indexes[i+j], indexes[i]
+	}
+	positions := indexes[:k]
+	sort.Ints(positions)
+	return positions, nil
+}
+
+// shuffle shuffles b in place with a Fisher-Yates shuffle
+func shuffle(b []byte) error {
+	for i := len(b) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return err
+		}
+		b[i], b[j] = b[j], b[i]
+	}
+	return nil
+}
+
+// log2Binomial returns log2 of n choose k
+func log2Binomial(n, k int) float64 {
+	if k < 0 || k > n {
+		return 0
+	}
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return (a - b - c) / math.Ln2
+}
+
+// removeAmbiguous removes the characters of ambiguousChars from chars
+func removeAmbiguous(chars string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(ambiguousChars, r) {
+			return -1
+		}
+		return r
+	}, chars)
+}
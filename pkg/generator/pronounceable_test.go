@@ -0,0 +1,107 @@
+package generator
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
+)
+
+func TestPronounceable(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    PronounceableOptions
+		wantErr bool
+	}{
+		{"Syllables", PronounceableOptions{Length: 12}, false},
+		{"Syllables with classes", PronounceableOptions{Length: 16, Upper: 2, Digits: 2, Symbols: 1}, false},
+		{"Trigrams", PronounceableOptions{Length: 14, Model: ModelTrigrams, Upper: 1, Digits: 1}, false},
+		{"Trigrams without ambiguous letters", PronounceableOptions{Length: 20, Model: ModelTrigrams, ExcludeAmbiguous: true, Digits: 3}, false},
+		{"No letters left", PronounceableOptions{Length: 3, Digits: 2, Symbols: 1}, true},
+		{"Too many uppercase letters", PronounceableOptions{Length: 6, Digits: 2, Upper: 5}, true},
+		{"Unknown model", PronounceableOptions{Length: 12, Model: "markov"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password, entropy, err := Pronounceable(tt.opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(password) != tt.opts.Length {
+				t.Errorf("Expected length %d, got %d: %s", tt.opts.Length, len(password), password)
+			}
+			counts := map[string]int{}
+			for _, char := range password {
+				switch s := string(char); {
+				case analyzer.ContainsUppercase(s):
+					counts["upper"]++
+				case analyzer.ContainsDigit(s):
+					counts["digits"]++
+				case analyzer.ContainsSymbol(s):
+					counts["symbols"]++
+				}
+			}
+			if counts["upper"] != tt.opts.Upper || counts["digits"] != tt.opts.Digits || counts["symbols"] != tt.opts.Symbols {
+				t.Errorf("Unexpected composition %v of %s", counts, password)
+			}
+			if tt.opts.ExcludeAmbiguous && strings.ContainsAny(password, ambiguousChars) {
+				t.Errorf("Password contains ambiguous characters: %s", password)
+			}
+			if entropy <= 0 {
+				t.Errorf("Expected positive entropy, got %v", entropy)
+			}
+		})
+	}
+}
+
+func TestPronounceableSyllables(t *testing.T) {
+	password, entropy, err := Pronounceable(PronounceableOptions{Length: 8})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i := 0; i < len(password); i++ {
+		set := syllableConsonants
+		if i%2 == 1 {
+			set = syllableVowels
+		}
+		if strings.IndexByte(set, password[i]) < 0 {
+			t.Errorf("Character %d of %s is not from %q", i, password, set)
+		}
+	}
+
+	// The entropy follows the sizes of the letter sets, not the length
+	want := 4*math.Log2(float64(len(syllableConsonants))) + 4*math.Log2(float64(len(syllableVowels)))
+	if math.Abs(entropy-want) > 1e-9 {
+		t.Errorf("entropy = %v, want %v", entropy, want)
+	}
+}
+
+func TestPronounceableEntropyOfInsertions(t *testing.T) {
+	_, plain, _ := Pronounceable(PronounceableOptions{Length: 6})
+	_, entropy, _ := Pronounceable(PronounceableOptions{Length: 8, Digits: 1, Symbols: 1})
+
+	// Same six letters, two characters at 8 choose 2 positions in either order
+	want := plain + math.Log2(10) + math.Log2(float64(len(analyzer.Symbols))) + math.Log2(28) + 1
+	if math.Abs(entropy-want) > 1e-9 {
+		t.Errorf("entropy = %v, want %v", entropy, want)
+	}
+}
+
+func TestTrainTrigrams(t *testing.T) {
+	model := trainTrigrams([]string{"abc", "abd", "drop-down"})
+	if model["^^"]['a'] != 2 || model["ab"]['c'] != 1 || model["ab"]['d'] != 1 {
+		t.Errorf("Unexpected model: %v", model)
+	}
+	if _, ok := model["^^"]['d']; ok {
+		t.Errorf("Words with non-letters should be skipped: %v", model)
+	}
+}
@@ -0,0 +1,338 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+
+// Package hasher hashes passwords for storage with argon2id, bcrypt, scrypt or
+// PBKDF2-SHA256 and verifies passwords against stored hashes. Hashes are
+// encoded in the PHC string format, bcrypt in its Modular Crypt Format.
+//
+//	encoded, err := hasher.Hash("correct horse", hasher.DefaultOptions(hasher.Argon2id))
+//	if err != nil {
+//		return err
+//	}
+//	ok, err := hasher.Verify("correct horse", encoded)
+package hasher
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Supported algorithms
+const (
+	Argon2id     = "argon2id"
+	Bcrypt       = "bcrypt"
+	Scrypt       = "scrypt"
+	PBKDF2SHA256 = "pbkdf2-sha256"
+)
+
+// Algorithms lists the supported algorithms, the recommended one first
+var Algorithms = []string{Argon2id, Bcrypt, Scrypt, PBKDF2SHA256}
+
+// Salt and key lengths in bytes
+const (
+	SaltLength = 16
+	KeyLength  = 32
+)
+
+// Upper limits of the cost parameters, far above the OWASP recommendations.
+// They apply to stored hashes too, so that verifying a crafted hash cannot
+// take all memory or run for days.
+const (
+	// MaxMemory is the most memory in KiB of an argon2id or scrypt hash, 4 GiB
+	MaxMemory = 4 << 20
+
+	// MaxTime is the most argon2id passes
+	MaxTime = 100
+
+	// MaxCost is the highest bcrypt cost, about 2^8 times the default
+	MaxCost = 20
+
+	// MaxParallelism is the highest scrypt parallelism p
+	MaxParallelism = 16
+
+	// MaxIterations is the most PBKDF2-SHA256 iterations
+	MaxIterations = 10_000_000
+)
+
+// ErrMalformedHash is returned when a stored hash cannot be parsed
+var ErrMalformedHash = errors.New("malformed hash")
+
+// Options selects the algorithm and its cost parameters. Only the parameters
+// of the selected algorithm are used.
+type Options struct {
+	Algorithm string
+
+	// argon2id: passes over memory, memory in KiB and lanes
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+
+	// bcrypt: log2 of the number of rounds
+	Cost int
+
+	// scrypt: CPU/memory cost N (a power of 2), block size r and parallelism p
+	N int
+	R int
+	P int
+
+	// PBKDF2-SHA256: number of iterations
+	Iterations int
+}
+
+// DefaultOptions returns the parameters recommended by the OWASP Password
+// Storage Cheat Sheet for the algorithm
+func DefaultOptions(algorithm string) Options {
+	return Options{
+		Algorithm:  algorithm,
+		Time:       2,
+		Memory:     19 * 1024,
+		Threads:    1,
+		Cost:       12,
+		N:          1 << 17,
+		R:          8,
+		P:          1,
+		Iterations: 600000,
+	}
+}
+
+// Validate checks the parameters of the selected algorithm
+func (o Options) Validate() error {
+	switch o.Algorithm {
+	case Argon2id:
+		if o.Time < 1 || o.Threads < 1 {
+			return fmt.Errorf("argon2id time and threads must be at least 1")
+		}
+		if o.Memory < 8*uint32(o.Threads) {
+			return fmt.Errorf("argon2id memory must be at least 8 KiB per thread")
+		}
+		if o.Memory > MaxMemory || o.Time > MaxTime {
+			return fmt.Errorf("argon2id memory must not exceed %d KiB and time %d", MaxMemory, MaxTime)
+		}
+	case Bcrypt:
+		if o.Cost < bcrypt.MinCost || o.Cost > MaxCost {
+			return fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, MaxCost)
+		}
+	case Scrypt:
+		if o.N < 2 || bits.OnesCount(uint(o.N)) != 1 {
+			return fmt.Errorf("scrypt N must be a power of 2 greater than 1")
+		}
+		if o.R < 1 || o.P < 1 || uint64(o.R)*uint64(o.P) >= 1<<30 {
+			return fmt.Errorf("scrypt r and p must be at least 1 with r*p < 2^30")
+		}
+		// Every hash uses 128*N*r bytes
+		if uint64(o.N) > MaxMemory*1024/128/uint64(o.R) || o.P > MaxParallelism {
+			return fmt.Errorf("scrypt must not use more than %d KiB, and p must not exceed %d", MaxMemory, MaxParallelism)
+		}
+	case PBKDF2SHA256:
+		if o.Iterations < 1 || o.Iterations > MaxIterations {
+			return fmt.Errorf("pbkdf2-sha256 iterations must be between 1 and %d", MaxIterations)
+		}
+	default:
+		return fmt.Errorf("unknown algorithm %q, expected one of: %s", o.Algorithm, strings.Join(Algorithms, ", "))
+	}
+	return nil
+}
+
+// Hash hashes password with a random salt and returns the encoded hash
+func Hash(password string, opts Options) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+
+	if opts.Algorithm == Bcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), opts.Cost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	}
+
+	salt := make([]byte, SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("error generating salt: %v", err)
+	}
+	key, err := deriveKey(password, salt, KeyLength, opts)
+	if err != nil {
+		return "", err
+	}
+	return encodePHC(opts, salt, key), nil
+}
+
+// Verify reports whether password matches the encoded hash. Keys are compared
+// in constant time. Hashes that cannot be parsed return ErrMalformedHash.
+func Verify(password, encoded string) (bool, error) {
+	opts, err := Identify(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	if opts.Algorithm == Bcrypt {
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	_, salt, key, err := decodePHC(encoded)
+	if err != nil {
+		return false, err
+	}
+	derived, err := deriveKey(password, salt, len(key), opts)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(derived, key) == 1, nil
+}
+
+// Identify returns the algorithm and parameters of an encoded hash
+func Identify(encoded string) (Options, error) {
+	if strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$") {
+		cost, err := bcrypt.Cost([]byte(encoded))
+		if err != nil {
+			return Options{}, fmt.Errorf("%w: %v", ErrMalformedHash, err)
+		}
+		opts := Options{Algorithm: Bcrypt, Cost: cost}
+		if err := opts.Validate(); err != nil {
+			return Options{}, fmt.Errorf("%w: %v", ErrMalformedHash, err)
+		}
+		return opts, nil
+	}
+
+	opts, _, _, err := decodePHC(encoded)
+	return opts, err
+}
+
+func deriveKey(password string, salt []byte, keyLength int, opts Options) ([]byte, error) {
+	switch opts.Algorithm {
+	case Argon2id:
+		return argon2.IDKey([]byte(password), salt, opts.Time, opts.Memory, opts.Threads, uint32(keyLength)), nil
+	case Scrypt:
+		return scrypt.Key([]byte(password), salt, opts.N, opts.R, opts.P, keyLength)
+	case PBKDF2SHA256:
+		return pbkdf2.Key([]byte(password), salt, opts.Iterations, keyLength, sha256.New), nil
+	default:
+		return nil, fmt.Errorf("unknown algorithm %q", opts.Algorithm)
+	}
+}
+
+// encodePHC encodes a hash as a PHC string:
+//
+//	$argon2id$v=19$m=19456,t=2,p=1$<salt>$<key>
+//	$scrypt$ln=17,r=8,p=1$<salt>$<key>
+//	$pbkdf2-sha256$i=600000$<salt>$<key>
+//
+// Salt and key are base64 encoded without padding.
+func encodePHC(opts Options, salt, key []byte) string {
+	params := opts.Params()
+	if opts.Algorithm == Argon2id {
+		params = fmt.Sprintf("v=%d$%s", argon2.Version, params)
+	}
+	return fmt.Sprintf("$%s$%s$%s$%s", opts.Algorithm, params, b64.EncodeToString(salt), b64.EncodeToString(key))
+}
+
+// Params returns the cost parameters of the selected algorithm as they appear
+// in an encoded hash, e.g. "m=19456,t=2,p=1" for argon2id
+func (o Options) Params() string {
+	switch o.Algorithm {
+	case Argon2id:
+		return fmt.Sprintf("m=%d,t=%d,p=%d", o.Memory, o.Time, o.Threads)
+	case Bcrypt:
+		return fmt.Sprintf("cost=%d", o.Cost)
+	case Scrypt:
+		return fmt.Sprintf("ln=%d,r=%d,p=%d", bits.TrailingZeros(uint(o.N)), o.R, o.P)
+	case PBKDF2SHA256:
+		return fmt.Sprintf("i=%d", o.Iterations)
+	default:
+		return ""
+	}
+}
+
+var b64 = base64.RawStdEncoding
+
+// decodePHC parses a PHC string written by encodePHC
+func decodePHC(encoded string) (Options, []byte, []byte, error) {
+	fields := strings.Split(encoded, "$")
+	if len(fields) < 5 || fields[0] != "" {
+		return Options{}, nil, nil, ErrMalformedHash
+	}
+	opts := Options{Algorithm: fields[1]}
+
+	if opts.Algorithm == Argon2id {
+		if len(fields) != 6 {
+			return Options{}, nil, nil, ErrMalformedHash
+		}
+		if fields[2] != fmt.Sprintf("v=%d", argon2.Version) {
+			return Options{}, nil, nil, fmt.Errorf("%w: unsupported argon2 version %s", ErrMalformedHash, fields[2])
+		}
+		fields = append(fields[:2], fields[3:]...)
+	}
+	if len(fields) != 5 {
+		return Options{}, nil, nil, ErrMalformedHash
+	}
+
+	params, err := parseParams(fields[2])
+	if err != nil {
+		return Options{}, nil, nil, err
+	}
+	switch opts.Algorithm {
+	case Argon2id:
+		opts.Memory, opts.Time = uint32(params["m"]), uint32(params["t"])
+		opts.Threads = uint8(params["p"])
+		if params["p"] > 255 {
+			return Options{}, nil, nil, fmt.Errorf("%w: argon2id parallelism %d", ErrMalformedHash, params["p"])
+		}
+	case Scrypt:
+		if params["ln"] < 1 || params["ln"] > 62 {
+			return Options{}, nil, nil, fmt.Errorf("%w: scrypt ln=%d", ErrMalformedHash, params["ln"])
+		}
+		opts.N, opts.R, opts.P = 1<<params["ln"], int(params["r"]), int(params["p"])
+	case PBKDF2SHA256:
+		opts.Iterations = int(params["i"])
+	default:
+		return Options{}, nil, nil, fmt.Errorf("%w: unsupported algorithm %q", ErrMalformedHash, opts.Algorithm)
+	}
+	if err := opts.Validate(); err != nil {
+		return Options{}, nil, nil, fmt.Errorf("%w: %v", ErrMalformedHash, err)
+	}
+
+	salt, err := b64.DecodeString(fields[3])
+	if err != nil {
+		return Options{}, nil, nil, fmt.Errorf("%w: salt: %v", ErrMalformedHash, err)
+	}
+	key, err := b64.DecodeString(fields[4])
+	if err != nil || len(key) == 0 {
+		return Options{}, nil, nil, fmt.Errorf("%w: invalid key", ErrMalformedHash)
+	}
+	return opts, salt, key, nil
+}
+
+// parseParams parses comma separated name=value parameters
+func parseParams(s string) (map[string]int64, error) {
+	params := make(map[string]int64)
+	for _, param := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: parameter %q", ErrMalformedHash, param)
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 0 || n > 1<<32-1 {
+			return nil, fmt.Errorf("%w: parameter %q", ErrMalformedHash, param)
+		}
+		params[name] = n
+	}
+	return params, nil
+}
@@ -0,0 +1,135 @@
+package hasher
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// cheapOptions keeps the cost of the tests low
+func cheapOptions(algorithm string) Options {
+	opts := DefaultOptions(algorithm)
+	opts.Time, opts.Memory = 1, 64
+	opts.Cost = 4
+	opts.N = 1 << 10
+	opts.Iterations = 1000
+	return opts
+}
+
+func TestHashAndVerify(t *testing.T) {
+	prefixes := map[string]string{
+		Argon2id:     "$argon2id$v=19$m=64,t=1,p=1$",
+		Bcrypt:       "$2a$04$",
+		Scrypt:       "$scrypt$ln=10,r=8,p=1$",
+		PBKDF2SHA256: "$pbkdf2-sha256$i=1000$",
+	}
+
+	for _, algorithm := range Algorithms {
+		t.Run(algorithm, func(t *testing.T) {
+			encoded, err := Hash("correct horse", cheapOptions(algorithm))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !strings.HasPrefix(encoded, prefixes[algorithm]) {
+				t.Errorf("Hash() = %s, want prefix %s", encoded, prefixes[algorithm])
+			}
+
+			if ok, err := Verify("correct horse", encoded); !ok || err != nil {
+				t.Errorf("Verify() with the right password = %v, %v", ok, err)
+			}
+			if ok, err := Verify("correct horsf", encoded); ok || err != nil {
+				t.Errorf("Verify() with a wrong password = %v, %v", ok, err)
+			}
+
+			again, _ := Hash("correct horse", cheapOptions(algorithm))
+			if again == encoded {
+				t.Errorf("Hashes of the same password should use different salts")
+			}
+		})
+	}
+}
+
+func TestVerifyReferenceHashes(t *testing.T) {
+	// Created with Python's hashlib
+	hashes := []string{
+		"$pbkdf2-sha256$i=1000$MDEyMzQ1Njc4OWFiY2RlZg$hRRjgXWkW8ResfIvBP99J/T4vkgEmMRV/0tJTOjR59I",
+		"$scrypt$ln=10,r=8,p=1$MDEyMzQ1Njc4OWFiY2RlZg$ZEBCzLptWM7dhpNJDU2HbQ945ovKHmVEozHkePPbSqw",
+	}
+	for _, encoded := range hashes {
+		if ok, err := Verify("password", encoded); !ok || err != nil {
+			t.Errorf("Verify(%s) = %v, %v", encoded, ok, err)
+		}
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	opts, err := Identify("$argon2id$v=19$m=65536,t=3,p=4$MDEyMzQ1Njc4OWFiY2RlZg$aGFzaA")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.Algorithm != Argon2id || opts.Memory != 65536 || opts.Time != 3 || opts.Threads != 4 {
+		t.Errorf("Identify() = %+v", opts)
+	}
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	hashes := []string{
+		"",
+		"plaintext",
+		"$md5$salt$hash",
+		"$argon2id$v=16$m=64,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=64,t=0,p=1$c2FsdA$aGFzaA",
+		"$scrypt$ln=10,r=8$c2FsdA$aGFzaA",
+		"$pbkdf2-sha256$i=abc$c2FsdA$aGFzaA",
+		"$pbkdf2-sha256$i=1000$c2FsdA$",
+		"$2a$99$invalid",
+	}
+	for _, encoded := range hashes {
+		if _, err := Verify("password", encoded); !errors.Is(err, ErrMalformedHash) {
+			t.Errorf("Verify(%q) error = %v, want ErrMalformedHash", encoded, err)
+		}
+	}
+}
+
+func TestVerifyHostile(t *testing.T) {
+	// Parameters that would take gigabytes of memory or days of CPU are
+	// rejected before the key is derived
+	hashes := []string{
+		"$argon2id$v=19$m=4294967295,t=1,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=64,t=4294967295,p=1$c2FsdA$aGFzaA",
+		"$scrypt$ln=40,r=8,p=1$c2FsdA$aGFzaA",
+		"$scrypt$ln=10,r=8,p=1000000$c2FsdA$aGFzaA",
+		"$pbkdf2-sha256$i=4294967295$c2FsdA$aGFzaA",
+		"$2a$31$abcdefghijklmnopqrstuuN3JLmTOERw0bJNRABszfaD3ysGTwRpi",
+	}
+	for _, encoded := range hashes {
+		if _, err := Verify("password", encoded); !errors.Is(err, ErrMalformedHash) {
+			t.Errorf("Verify(%q) error = %v, want ErrMalformedHash", encoded, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Options)
+		wantErr bool
+	}{
+		{"Defaults", func(o *Options) {}, false},
+		{"Unknown algorithm", func(o *Options) { o.Algorithm = "md5" }, true},
+		{"Scrypt N not a power of 2", func(o *Options) { o.Algorithm, o.N = Scrypt, 1000 }, true},
+		{"Bcrypt cost too high", func(o *Options) { o.Algorithm, o.Cost = Bcrypt, 32 }, true},
+		{"Argon2id without memory", func(o *Options) { o.Memory = 0 }, true},
+		{"PBKDF2 without iterations", func(o *Options) { o.Algorithm, o.Iterations = PBKDF2SHA256, 0 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions(Argon2id)
+			tt.modify(&opts)
+			if err := opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
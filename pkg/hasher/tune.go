@@ -0,0 +1,153 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+package hasher
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TuneOptions controls Tune
+type TuneOptions struct {
+	// Target is the hashing time to approach without exceeding it
+	Target time.Duration
+
+	// MemoryMax is the most memory in KiB a single argon2id or scrypt hash
+	// may use
+	MemoryMax uint32
+
+	// Threads is the argon2id parallelism, 1 when 0
+	Threads uint8
+}
+
+// Recommendation is the tuned cost of an algorithm with the time a hash took
+// on the current machine
+type Recommendation struct {
+	Options  Options
+	Duration time.Duration
+}
+
+// TunedAlgorithms lists the algorithms Tune recommends parameters for.
+// PBKDF2 is left out because it is not memory hard.
+var TunedAlgorithms = []string{Argon2id, Bcrypt, Scrypt}
+
+// Tune runs trial hashes on the current machine and recommends the most
+// expensive parameters of every algorithm of TunedAlgorithms that hash within
+// opts.Target and opts.MemoryMax. When even the cheapest parameters exceed
+// the target, those are recommended.
+func Tune(opts TuneOptions) ([]Recommendation, error) {
+	if opts.Target <= 0 {
+		return nil, fmt.Errorf("target must be greater than 0")
+	}
+	if opts.Threads == 0 {
+		opts.Threads = 1
+	}
+	if opts.MemoryMax < 8*uint32(opts.Threads) {
+		return nil, fmt.Errorf("memory limit must be at least 8 KiB per thread")
+	}
+	if opts.MemoryMax > MaxMemory {
+		return nil, fmt.Errorf("memory limit must not exceed %d KiB", MaxMemory)
+	}
+
+	var recommendations []Recommendation
+	for _, tune := range []func(TuneOptions) (Recommendation, error){tuneArgon2id, tuneBcrypt, tuneScrypt} {
+		recommendation, err := tune(opts)
+		if err != nil {
+			return nil, err
+		}
+		recommendations = append(recommendations, recommendation)
+	}
+	return recommendations, nil
+}
+
+// tuneArgon2id uses as much memory as allowed, as recommended by RFC 9106,
+// halving it until a single pass fits the target, then adds passes
+func tuneArgon2id(opts TuneOptions) (Recommendation, error) {
+	candidate := Options{Algorithm: Argon2id, Time: 1, Memory: opts.MemoryMax, Threads: opts.Threads}
+	minMemory := 8 * uint32(opts.Threads)
+
+	duration, err := measure(candidate)
+	for err == nil && duration > opts.Target && candidate.Memory/2 >= minMemory {
+		candidate.Memory /= 2
+		duration, err = measure(candidate)
+	}
+	if err != nil {
+		return Recommendation{}, err
+	}
+
+	// Time scales linearly with the passes
+	if passes := min(uint32(opts.Target/duration), MaxTime); passes > 1 {
+		candidate.Time = passes
+		if duration, err = measure(candidate); err != nil {
+			return Recommendation{}, err
+		}
+		for duration > opts.Target && candidate.Time > 1 {
+			candidate.Time--
+			if duration, err = measure(candidate); err != nil {
+				return Recommendation{}, err
+			}
+		}
+	}
+	return Recommendation{Options: candidate, Duration: duration}, nil
+}
+
+// tuneBcrypt raises the cost, which doubles the time, until the target is exceeded
+func tuneBcrypt(opts TuneOptions) (Recommendation, error) {
+	return tuneDoubling(opts, Options{Algorithm: Bcrypt, Cost: bcrypt.MinCost}, func(o Options) (Options, bool) {
+		o.Cost++
+		return o, o.Cost <= MaxCost
+	})
+}
+
+// tuneScrypt doubles N, and with it time and memory, until the target or the
+// memory limit is exceeded. Every hash uses 128*N*r bytes.
+func tuneScrypt(opts TuneOptions) (Recommendation, error) {
+	return tuneDoubling(opts, Options{Algorithm: Scrypt, N: 2, R: 8, P: 1}, func(o Options) (Options, bool) {
+		o.N *= 2
+		return o, uint64(128*o.N*o.R) <= uint64(opts.MemoryMax)*1024
+	})
+}
+
+// tuneDoubling steps from the cheapest candidate to the most expensive one
+// that fits the target. next returns the following candidate and whether it
+// is allowed at all.
+func tuneDoubling(opts TuneOptions, candidate Options, next func(Options) (Options, bool)) (Recommendation, error) {
+	duration, err := measure(candidate)
+	if err != nil {
+		return Recommendation{}, err
+	}
+	for {
+		following, ok := next(candidate)
+		if !ok {
+			break
+		}
+		followingDuration, err := measure(following)
+		if err != nil {
+			return Recommendation{}, err
+		}
+		if followingDuration > opts.Target {
+			break
+		}
+		candidate, duration = following, followingDuration
+	}
+	return Recommendation{Options: candidate, Duration: duration}, nil
+}
+
+// measure returns the fastest of two hashes with the parameters, so that a
+// single slow run does not lower the recommendation
+func measure(opts Options) (time.Duration, error) {
+	var fastest time.Duration
+	for i := 0; i < 2; i++ {
+		start := time.Now()
+		if _, err := Hash("password-zen tune", opts); err != nil {
+			return 0, err
+		}
+		if elapsed := time.Since(start); i == 0 || elapsed < fastest {
+			fastest = elapsed
+		}
+	}
+	return fastest, nil
+}
@@ -0,0 +1,44 @@
+package hasher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTune(t *testing.T) {
+	recommendations, err := Tune(TuneOptions{Target: 10 * time.Millisecond, MemoryMax: 4096})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(recommendations) != len(TunedAlgorithms) {
+		t.Fatalf("Expected %d recommendations, got %d", len(TunedAlgorithms), len(recommendations))
+	}
+
+	for i, recommendation := range recommendations {
+		opts := recommendation.Options
+		if opts.Algorithm != TunedAlgorithms[i] {
+			t.Errorf("Recommendation %d is for %s, want %s", i, opts.Algorithm, TunedAlgorithms[i])
+		}
+		if err := opts.Validate(); err != nil {
+			t.Errorf("Invalid %s recommendation: %v", opts.Algorithm, err)
+		}
+		if recommendation.Duration <= 0 {
+			t.Errorf("Expected a measured duration for %s", opts.Algorithm)
+		}
+		if opts.Algorithm == Argon2id && opts.Memory > 4096 {
+			t.Errorf("argon2id memory %d exceeds the limit", opts.Memory)
+		}
+		if opts.Algorithm == Scrypt && 128*opts.N*opts.R > 4096*1024 {
+			t.Errorf("scrypt N=%d r=%d exceeds the memory limit", opts.N, opts.R)
+		}
+	}
+}
+
+func TestTuneInvalid(t *testing.T) {
+	if _, err := Tune(TuneOptions{Target: 0, MemoryMax: 4096}); err == nil {
+		t.Error("Expected an error for a zero target")
+	}
+	if _, err := Tune(TuneOptions{Target: time.Millisecond, MemoryMax: 4}); err == nil {
+		t.Error("Expected an error for a memory limit below 8 KiB")
+	}
+}
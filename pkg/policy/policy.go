@@ -0,0 +1,301 @@
+/*
+Copyright © 2025 Mahadeva Sankaram
+*/
+
+// Package policy reads declarative password policies from YAML or JSON and
+// turns them into analyzer criteria and generator settings.
+//
+//	p, err := policy.Load("nist-800-63b")
+//	if err != nil {
+//		return err
+//	}
+//	result, err := analyzer.Analyze(password, p.Criteria())
+package policy
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
+	"github.com/tmsankaram/password-zen/pkg/generator"
+)
+
+// presets holds the built-in policies, one YAML file per preset
+//
+//go:embed presets/*.yaml
+var presets embed.FS
+
+// MaxGenerateAttempts bounds how often a generated password is retried when it
+// fails a policy rule that cannot be guaranteed by construction
+const MaxGenerateAttempts = 100
+
+// Policy is a declarative password policy read from YAML or JSON.
+// Zero values disable the corresponding rule.
+type Policy struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description,omitempty"`
+	MinLength   int      `yaml:"min_length,omitempty"`
+	MaxLength   int      `yaml:"max_length,omitempty"`
+	MinLower    int      `yaml:"min_lower,omitempty"`
+	MinUpper    int      `yaml:"min_upper,omitempty"`
+	MinLetters  int      `yaml:"min_letters,omitempty"`
+	MinDigits   int      `yaml:"min_digits,omitempty"`
+	MinSymbols  int      `yaml:"min_symbols,omitempty"`
+	MinClasses  int      `yaml:"min_classes,omitempty"`
+	BannedWords []string `yaml:"banned_words,omitempty"`
+	MaxRepeated int      `yaml:"max_repeated,omitempty"`
+	MinEntropy  float64  `yaml:"min_entropy,omitempty"`
+	MinScore    int      `yaml:"min_score,omitempty"`
+	Breach      Breach   `yaml:"breach,omitempty"`
+
+	MaxKeyboardWalk int `yaml:"max_keyboard_walk,omitempty"`
+	MaxSequence     int `yaml:"max_sequence,omitempty"`
+	MaxRepetition   int `yaml:"max_repetition,omitempty"`
+}
+
+// Breach configures the breached password check of a policy
+type Breach struct {
+	Enabled  bool   `yaml:"enabled,omitempty"`
+	Database string `yaml:"database,omitempty"`
+	MaxCount int    `yaml:"max_count,omitempty"`
+}
+
+// Presets returns the names of the built-in presets
+func Presets() []string {
+	entries, _ := presets.ReadDir("presets")
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load loads a built-in preset by name or a policy file by path.
+// A relative breach database path in a file is resolved against the file's directory.
+func Load(nameOrPath string) (Policy, error) {
+	data, err := presets.ReadFile("presets/" + nameOrPath + ".yaml")
+	fromFile := err != nil
+	if fromFile {
+		info, err := os.Stat(nameOrPath)
+		if os.IsNotExist(err) {
+			return Policy{}, fmt.Errorf("file does not exist: %s (built-in presets: %s)", nameOrPath, strings.Join(Presets(), ", "))
+		} else if err == nil && info.IsDir() {
+			return Policy{}, fmt.Errorf("path is a directory, not a file: %s", nameOrPath)
+		}
+		if data, err = os.ReadFile(nameOrPath); err != nil {
+			return Policy{}, fmt.Errorf("cannot read file: %v", err)
+		}
+	}
+
+	policy, err := Parse(data)
+	if err != nil {
+		return policy, fmt.Errorf("invalid policy %s: %v", nameOrPath, err)
+	}
+
+	if policy.Name == "" {
+		policy.Name = strings.TrimSuffix(filepath.Base(nameOrPath), filepath.Ext(nameOrPath))
+	}
+	if db := policy.Breach.Database; db != "" && !filepath.IsAbs(db) && fromFile {
+		policy.Breach.Database = filepath.Join(filepath.Dir(nameOrPath), db)
+	}
+
+	return policy, policy.Validate()
+}
+
+// Parse decodes a YAML or JSON policy. Unknown fields are an error so that
+// typos do not silently disable a rule. The policy is not validated.
+func Parse(data []byte) (Policy, error) {
+	var policy Policy
+
+	// YAML is a superset of JSON, so both are read by the same decoder
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&policy); err != nil {
+		return policy, err
+	}
+	return policy, nil
+}
+
+// Validate checks that the policy can be satisfied
+func (p Policy) Validate() error {
+	for _, field := range []struct {
+		name  string
+		value int
+	}{
+		{"min_length", p.MinLength},
+		{"max_length", p.MaxLength},
+		{"min_lower", p.MinLower},
+		{"min_upper", p.MinUpper},
+		{"min_letters", p.MinLetters},
+		{"min_digits", p.MinDigits},
+		{"min_symbols", p.MinSymbols},
+		{"min_classes", p.MinClasses},
+		{"max_repeated", p.MaxRepeated},
+		{"max_keyboard_walk", p.MaxKeyboardWalk},
+		{"max_sequence", p.MaxSequence},
+		{"max_repetition", p.MaxRepetition},
+		{"breach.max_count", p.Breach.MaxCount},
+	} {
+		if field.value < 0 {
+			return fmt.Errorf("policy %s: %s must not be negative", p.Name, field.name)
+		}
+	}
+
+	if p.MinClasses > 4 {
+		return fmt.Errorf("policy %s: min_classes must be between 0 and 4", p.Name)
+	}
+	if p.MinScore < 0 || p.MinScore > 4 {
+		return fmt.Errorf("policy %s: min_score must be between 0 and 4", p.Name)
+	}
+	if p.MinEntropy < 0 {
+		return fmt.Errorf("policy %s: min_entropy must not be negative", p.Name)
+	}
+
+	if p.MaxLength > 0 {
+		if p.MaxLength < p.MinLength {
+			return fmt.Errorf("policy %s: max_length (%d) is less than min_length (%d)", p.Name, p.MaxLength, p.MinLength)
+		}
+		required := p.MinDigits + p.MinSymbols + max(p.MinLower+p.MinUpper, p.MinLetters)
+		if required > p.MaxLength {
+			return fmt.Errorf("policy %s: character class minimums (%d) exceed max_length (%d)", p.Name, required, p.MaxLength)
+		}
+	}
+
+	return nil
+}
+
+// Criteria converts the policy into analysis criteria. The breach check is
+// left to the caller, who opens the database returned by BreachDatabase.
+func (p Policy) Criteria() analyzer.Criteria {
+	bannedWords := make([]string, 0, len(p.BannedWords))
+	for _, word := range p.BannedWords {
+		if word = strings.TrimSpace(word); word != "" {
+			bannedWords = append(bannedWords, word)
+		}
+	}
+
+	return analyzer.Criteria{
+		MinLength:      p.MinLength,
+		MaxLength:      p.MaxLength,
+		MinLower:       p.MinLower,
+		MinUpper:       p.MinUpper,
+		MinLetters:     p.MinLetters,
+		MinDigits:      p.MinDigits,
+		MinSymbols:     p.MinSymbols,
+		MinClasses:     p.MinClasses,
+		BannedWords:    bannedWords,
+		MaxRepeated:    p.MaxRepeated,
+		MinEntropy:     p.MinEntropy,
+		MinScore:       p.MinScore,
+		MaxBreachCount: p.Breach.MaxCount,
+
+		MaxKeyboardWalk: p.MaxKeyboardWalk,
+		MaxSequence:     p.MaxSequence,
+		MaxRepetition:   p.MaxRepetition,
+	}
+}
+
+// BreachDatabase returns the breach database to use with the policy, preferring
+// an explicitly given path. An empty result means the breach check is skipped.
+func (p Policy) BreachDatabase(path string) string {
+	if path != "" {
+		return path
+	}
+	if p.Breach.Enabled {
+		return p.Breach.Database
+	}
+	return ""
+}
+
+// GenerateOptions controls how a password satisfying a policy is generated
+type GenerateOptions struct {
+	Length int
+
+	// Digits and symbols are included anyway when the policy requires them
+	IncludeDigits    bool
+	IncludeSymbols   bool
+	ExcludeAmbiguous bool
+
+	// Breach rejects breached passwords when set
+	Breach analyzer.BreachChecker
+}
+
+// CharClasses returns the character classes Generate draws passwords from,
+// with the minimums required by the policy
+func (p Policy) CharClasses(opts GenerateOptions) ([]generator.CharClass, error) {
+	classOpts := generator.Options{
+		IncludeDigits:    opts.IncludeDigits || p.MinDigits > 0,
+		IncludeSymbols:   opts.IncludeSymbols || p.MinSymbols > 0 || p.MinClasses == 4,
+		ExcludeAmbiguous: opts.ExcludeAmbiguous,
+		MinLower:         p.MinLower,
+		MinUpper:         p.MinUpper,
+		MinDigits:        p.MinDigits,
+		MinSymbols:       p.MinSymbols,
+	}
+	// Letters can be satisfied by either case
+	if p.MinLetters > p.MinLower+p.MinUpper {
+		classOpts.MinLower += p.MinLetters - p.MinLower - p.MinUpper
+	}
+	classes := classOpts.Classes()
+
+	used := 0
+	for _, class := range classes {
+		if class.Min > 0 {
+			used++
+		}
+	}
+	// Require one character from further classes until min_classes is met
+	for i := range classes {
+		if used >= p.MinClasses {
+			break
+		}
+		if classes[i].Min == 0 {
+			classes[i].Min = 1
+			used++
+		}
+	}
+	if used < p.MinClasses {
+		return nil, fmt.Errorf("policy requires %d character classes but only %d are enabled", p.MinClasses, used)
+	}
+	return classes, nil
+}
+
+// Generate generates a password of the given length that satisfies the policy
+func (p Policy) Generate(opts GenerateOptions) (string, error) {
+	if opts.Length < p.MinLength || (p.MaxLength > 0 && opts.Length > p.MaxLength) {
+		return "", fmt.Errorf("length %d is outside the range allowed by policy %s (min_length %d, max_length %d)", opts.Length, p.Name, p.MinLength, p.MaxLength)
+	}
+
+	classes, err := p.CharClasses(opts)
+	if err != nil {
+		return "", err
+	}
+
+	criteria := p.Criteria()
+	criteria.Breach = opts.Breach
+
+	for attempt := 0; attempt < MaxGenerateAttempts; attempt++ {
+		password, err := generator.Compose(opts.Length, generator.JoinCharClasses(classes), classes)
+		if err != nil {
+			return "", err
+		}
+
+		result, err := analyzer.Analyze(password, criteria)
+		if err != nil {
+			return "", err
+		}
+		if result.Passed {
+			return password, nil
+		}
+	}
+
+	return "", fmt.Errorf("no password satisfying policy %s found after %d attempts, try a longer length", p.Name, MaxGenerateAttempts)
+}
@@ -0,0 +1,107 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tmsankaram/password-zen/pkg/analyzer"
+)
+
+func TestPolicyPresets(t *testing.T) {
+	names := Presets()
+	for _, want := range []string{"nist-800-63b", "pci-dss", "cis", "legacy-ad"} {
+		found := false
+		for _, name := range names {
+			if name == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Missing built-in preset %s, got %v", want, names)
+		}
+	}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			policy, err := Load(name)
+			if err != nil {
+				t.Fatalf("Unexpected error loading preset: %v", err)
+			}
+
+			// Generated passwords must satisfy the policy they were generated for
+			length := max(12, policy.MinLength)
+			for run := 0; run < 10; run++ {
+				password, err := policy.Generate(GenerateOptions{Length: length, IncludeDigits: true})
+				if err != nil {
+					t.Fatalf("Unexpected error generating password: %v", err)
+				}
+				result, _ := analyzer.Analyze(password, policy.Criteria())
+				if !result.Passed {
+					t.Errorf("Generated password %q fails policy %s: %+v", password, name, result.Checks)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	yamlPath := filepath.Join(dir, "team.yaml")
+	os.WriteFile(yamlPath, []byte("min_length: 10\nmin_symbols: 2\nbanned_words: [acme]\nmax_keyboard_walk: 4\nmax_sequence: 3\nmax_repetition: 5\nbreach:\n  enabled: true\n  database: pwned.pzdb\n"), 0644)
+	jsonPath := filepath.Join(dir, "team.json")
+	os.WriteFile(jsonPath, []byte(`{"name": "json-team", "min_length": 10, "min_entropy": 40}`), 0644)
+
+	policy, err := Load(yamlPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if policy.Name != "team" || policy.MinLength != 10 || policy.MinSymbols != 2 || len(policy.BannedWords) != 1 {
+		t.Errorf("Unexpected policy: %+v", policy)
+	}
+	if policy.Breach.Database != filepath.Join(dir, "pwned.pzdb") {
+		t.Errorf("Expected breach database relative to policy file, got %s", policy.Breach.Database)
+	}
+	if criteria := policy.Criteria(); criteria.MaxKeyboardWalk != 4 || criteria.MaxSequence != 3 || criteria.MaxRepetition != 5 {
+		t.Errorf("Unexpected pattern criteria: %+v", criteria)
+	}
+
+	policy, err = Load(jsonPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if policy.Name != "json-team" || policy.MinEntropy != 40 {
+		t.Errorf("Unexpected policy: %+v", policy)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"Unknown field", "min_lenght: 10\n"},
+		{"Negative minimum", "min_digits: -1\n"},
+		{"Negative pattern length", "max_sequence: -1\n"},
+		{"Max below min", "min_length: 10\nmax_length: 8\n"},
+		{"Class minimums exceed max length", "max_length: 4\nmin_digits: 3\nmin_symbols: 3\n"},
+		{"Too many classes", "min_classes: 5\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "policy.yaml")
+			os.WriteFile(path, []byte(tt.content), 0644)
+			if _, err := Load(path); err == nil {
+				t.Errorf("Expected error but got none")
+			}
+		})
+	}
+
+	if _, err := Load("no-such-preset"); err == nil {
+		t.Errorf("Expected error for unknown preset")
+	}
+}